@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/wellcom-rocks/updates-sucks/pkg/config"
@@ -21,8 +26,19 @@ or a specific repository by name.`,
 	RunE: runScan,
 }
 
+var (
+	concurrency int
+	timeout     time.Duration
+	scanTimeout time.Duration
+	failOn      string
+)
+
 func init() {
 	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of repositories to scan in parallel")
+	scanCmd.Flags().DurationVar(&timeout, "timeout", 0, "Timeout per repository, overridden by a repository's \"timeout\" setting (0 disables)")
+	scanCmd.Flags().DurationVar(&scanTimeout, "scan-timeout", 0, "Deadline for the whole scan (0 disables)")
+	scanCmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with code 1 only for updates of at least this type (major, minor, patch)")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
@@ -36,8 +52,20 @@ func runScan(cmd *cobra.Command, args []string) error {
 		os.Exit(2) // Configuration error
 	}
 
+	if concurrency < 1 {
+		fmt.Fprintf(os.Stderr, "Configuration error: --concurrency must be at least 1\n")
+		os.Exit(2) // Configuration error
+	}
+
+	// Updates below the --fail-on threshold do not fail the scan
+	severity, err := failSeverity(failOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(2) // Configuration error
+	}
+
 	// Initialize scanner
-	gitScanner := scanner.NewGitScanner(verbose)
+	versionScanner := scanner.NewScanner(verbose)
 
 	// Determine which repositories to scan
 	var reposToScan []config.Repository
@@ -59,70 +87,225 @@ func runScan(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Scanning %d repositories...\n\n", len(reposToScan))
 	}
 
+	ctx := context.Background()
+	if scanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, scanTimeout)
+		defer cancel()
+	}
+
 	// Scan repositories
-	var results []output.ScanResult
-	hasUpdates := false
-	hasErrors := false
+	results := scanRepositories(ctx, versionScanner, reposToScan, concurrency, os.Stdout)
 
-	for _, repo := range reposToScan {
-		result := output.ScanResult{
-			Name:           repo.Name,
-			CurrentVersion: repo.CurrentVersion,
-		}
+	// Output results
+	jsonOutput := outputFormat == "json"
+	formatter := output.NewFormatter(jsonOutput, quiet, verbose)
+	formatter.PrintResults(results)
 
-		// Get latest version
-		latestVersion, err := gitScanner.GetLatestVersion(&repo)
-		if err != nil {
-			result.Status = "ERROR"
-			result.Error = err.Error()
-			hasErrors = true
-			if verbose {
-				fmt.Printf("Error scanning %s: %v\n", repo.Name, err)
-			}
-		} else {
-			result.LatestVersion = latestVersion
-
-			// Compare versions
-			needsUpdate, err := compareVersions(repo.CurrentVersion, latestVersion, repo.Versioning)
-			if err != nil {
-				result.Status = "ERROR"
-				result.Error = fmt.Sprintf("Version comparison error: %v", err)
-				hasErrors = true
-			} else if needsUpdate {
-				result.Status = "UPDATE_AVAILABLE"
+	if code := exitCode(results, severity); code != 0 {
+		os.Exit(code)
+	}
+
+	return nil // Success, no updates
+}
+
+// failSeverity returns the severity of the least severe update type that
+// fails the scan for a --fail-on value, which defaults to any update.
+func failSeverity(failOn string) (int, error) {
+	if failOn == "" {
+		return version.UpdatePatch.Severity(), nil
+	}
+	switch updateType := version.UpdateType(failOn); updateType {
+	case version.UpdateMajor, version.UpdateMinor, version.UpdatePatch:
+		return updateType.Severity(), nil
+	default:
+		return 0, fmt.Errorf("--fail-on must be one of major, minor, patch")
+	}
+}
+
+// exitCode determines the exit code of a scan from its results.
+func exitCode(results []output.ScanResult, failSeverity int) int {
+	hasUpdates := false
+	hasErrors := false
+	for _, result := range results {
+		switch result.Status {
+		case "UPDATE_AVAILABLE":
+			// Updates whose type is unknown always count
+			if result.UpdateType == "" || version.UpdateType(result.UpdateType).Severity() >= failSeverity {
 				hasUpdates = true
-			} else {
-				result.Status = "UP_TO_DATE"
 			}
+		case "ERROR", "TIMEOUT":
+			hasErrors = true
 		}
-
-		results = append(results, result)
 	}
 
-	// Output results
-	jsonOutput := outputFormat == "json"
-	formatter := output.NewFormatter(jsonOutput, quiet, verbose)
-	formatter.PrintResults(results)
-
-	// Determine exit code
 	if hasErrors {
-		os.Exit(3) // Scan error
+		return 3 // Scan error
 	} else if hasUpdates {
-		os.Exit(1) // Updates available
+		return 1 // Updates available
 	}
+	return 0
+}
 
-	return nil // Success, no updates
+// scanRepositories scans repos using up to concurrency workers, printing
+// verbose output to out. Results are returned in the same order as repos
+// regardless of completion order.
+func scanRepositories(ctx context.Context, versionScanner *scanner.Scanner, repos []config.Repository, concurrency int, out io.Writer) []output.ScanResult {
+	results := make([]output.ScanResult, len(repos))
+	jobs := make(chan int)
+	ctx = scanner.WithLogOutput(ctx, out)
+
+	// Verbose output of concurrent scans would interleave, so buffer it per
+	// repository and print it in config order instead
+	var logs *orderedOutput
+	if verbose && concurrency > 1 && len(repos) > 1 {
+		logs = newOrderedOutput(out, len(repos))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(repos); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if logs == nil {
+					results[i] = scanRepository(ctx, versionScanner, &repos[i], out)
+					continue
+				}
+				out := logs.buffer(i)
+				results[i] = scanRepository(scanner.WithLogOutput(ctx, out), versionScanner, &repos[i], out)
+				logs.done(i)
+			}
+		}()
+	}
+
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
 }
 
-func compareVersions(current, latest string, versioning *config.Versioning) (bool, error) {
-	// Remove prefix if configured
-	currentCmp := current
-	latestCmp := latest
+// orderedOutput collects output in one buffer per repository and writes
+// the buffers in order as soon as all earlier ones are complete.
+type orderedOutput struct {
+	mu       sync.Mutex
+	w        io.Writer
+	buffers  []bytes.Buffer
+	complete []bool
+	next     int
+}
 
-	if versioning != nil && versioning.IgnorePrefix != "" {
-		currentCmp = strings.TrimPrefix(current, versioning.IgnorePrefix)
-		latestCmp = strings.TrimPrefix(latest, versioning.IgnorePrefix)
+func newOrderedOutput(w io.Writer, n int) *orderedOutput {
+	return &orderedOutput{
+		w:        w,
+		buffers:  make([]bytes.Buffer, n),
+		complete: make([]bool, n),
 	}
+}
+
+// buffer returns the buffer of the i-th repository. It must only be used
+// by one goroutine until done(i) is called.
+func (o *orderedOutput) buffer(i int) *bytes.Buffer {
+	return &o.buffers[i]
+}
+
+// done marks the output of the i-th repository complete and writes the
+// buffers that are next in order.
+func (o *orderedOutput) done(i int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.complete[i] = true
+	for o.next < len(o.buffers) && o.complete[o.next] {
+		o.w.Write(o.buffers[o.next].Bytes())
+		o.buffers[o.next] = bytes.Buffer{}
+		o.next++
+	}
+}
+
+// scanRepository scans repo and compares its latest versions with the
+// current version, printing verbose output to out.
+func scanRepository(ctx context.Context, versionScanner *scanner.Scanner, repo *config.Repository, out io.Writer) output.ScanResult {
+	result := output.ScanResult{
+		Name:           repo.Name,
+		CurrentVersion: repo.CurrentVersion,
+	}
+
+	repoTimeout := timeout
+	if repo.Timeout > 0 {
+		repoTimeout = time.Duration(repo.Timeout)
+	}
+	if repoTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, repoTimeout)
+		defer cancel()
+	}
+
+	// Get latest version
+	scanned, err := versionScanner.Scan(ctx, repo)
+	result.Attempts = scanned.Attempts
+	if err != nil {
+		result.Status = "ERROR"
+		// Sources killed at the deadline, such as git ls-remote, may not
+		// wrap ctx.Err(), while HTTP client timeouts wrap a deadline error
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Status = "TIMEOUT"
+		}
+		result.Error = err.Error()
+		if verbose {
+			fmt.Fprintf(out, "Error scanning %s: %v\n", repo.Name, err)
+		}
+		return result
+	}
+
+	result.LatestVersion = scanned.LatestVersion
+
+	// With an update policy, only versions it allows count as updates
+	target := scanned.LatestVersion
+	if repo.UpdatePolicy != "" {
+		result.UpdatePolicy = repo.UpdatePolicy
+		result.LatestInRange = scanned.LatestInRange
+		target = scanned.LatestInRange
+		if target == "" {
+			result.Status = "UP_TO_DATE"
+			return result
+		}
+	}
+
+	// Compare versions
+	needsUpdate, err := compareVersions(repo.CurrentVersion, target, repo.Versioning)
+	if err != nil {
+		result.Status = "ERROR"
+		result.Error = fmt.Sprintf("Version comparison error: %v", err)
+	} else if needsUpdate {
+		result.Status = "UPDATE_AVAILABLE"
+		result.UpdateType = string(classifyUpdate(repo.CurrentVersion, target, repo.Versioning))
+	} else {
+		result.Status = "UP_TO_DATE"
+	}
+
+	return result
+}
+
+// classifyUpdate returns the type of the update from current to latest.
+func classifyUpdate(current, latest string, versioning *config.Versioning) version.UpdateType {
+	scheme := "semver"
+	if versioning != nil && versioning.Scheme != "" {
+		scheme = versioning.Scheme
+	}
+	if scheme == "string" {
+		return version.UpdateNone
+	}
+	return version.Classify(scanner.VersionFromTag(versioning, current), scanner.VersionFromTag(versioning, latest), scheme)
+}
+
+func compareVersions(current, latest string, versioning *config.Versioning) (bool, error) {
+	// Extract the versions and remove the prefix if configured
+	currentCmp := scanner.VersionFromTag(versioning, current)
+	latestCmp := scanner.VersionFromTag(versioning, latest)
 
 	// Get versioning scheme
 	scheme := "semver"
@@ -146,6 +329,41 @@ func compareVersions(current, latest string, versioning *config.Versioning) (boo
 		}
 		return result == version.Less, nil
 
+	case "pep440":
+		result, err := version.ComparePEP440(currentCmp, latestCmp)
+		if err != nil {
+			return false, err
+		}
+		return result == version.Less, nil
+
+	case "maven":
+		result, err := version.CompareMaven(currentCmp, latestCmp)
+		if err != nil {
+			return false, err
+		}
+		return result == version.Less, nil
+
+	case "debian":
+		result, err := version.CompareDebian(currentCmp, latestCmp)
+		if err != nil {
+			return false, err
+		}
+		return result == version.Less, nil
+
+	case "rpm":
+		result, err := version.CompareRPM(currentCmp, latestCmp)
+		if err != nil {
+			return false, err
+		}
+		return result == version.Less, nil
+
+	case "loose":
+		result, err := version.CompareLoose(currentCmp, latestCmp)
+		if err != nil {
+			return false, err
+		}
+		return result == version.Less, nil
+
 	case "string":
 		result, err := version.CompareString(currentCmp, latestCmp)
 		if err != nil {
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+	"github.com/wellcom-rocks/updates-sucks/pkg/output"
+	"github.com/wellcom-rocks/updates-sucks/pkg/scanner"
+	"github.com/wellcom-rocks/updates-sucks/pkg/version"
+)
+
+// fakeSource sleeps for the duration given as a repository's URL, fails
+// the first attempt of each repository and then lists fakeTags.
+type fakeSource struct {
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+var fakeTags = []string{"v1.0.0", "v1.1.0", "v2.0.0-rc.1"}
+
+func init() {
+	scanner.RegisterSource("fake", func(verbose bool) scanner.Source {
+		return &fakeSource{attempts: make(map[string]int)}
+	})
+	scanner.RegisterSource("fake-error", func(verbose bool) scanner.Source {
+		return errorSource{}
+	})
+}
+
+func (f *fakeSource) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	delay, err := time.ParseDuration(repo.URL)
+	if err != nil {
+		return nil, scanner.Permanent(err)
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(delay):
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts[repo.Name]++
+	if f.attempts[repo.Name] == 1 {
+		return nil, errors.New("connection reset")
+	}
+	return fakeTags, nil
+}
+
+func fakeRepository(name string, delay time.Duration) config.Repository {
+	return config.Repository{
+		Name:           name,
+		Type:           "fake",
+		URL:            delay.String(),
+		CurrentVersion: "v1.0.0",
+		Versioning: &config.Versioning{
+			Scheme:          "semver",
+			IgnorePrefix:    "v",
+			ExcludePatterns: []string{"-rc"},
+		},
+		Retry: &config.Retry{MaxAttempts: 2, InitialBackoff: config.Duration(10 * time.Millisecond)},
+	}
+}
+
+func TestScanRepositoriesOrder(t *testing.T) {
+	defer func(v bool) { verbose = v }(verbose)
+	verbose = true
+
+	// The repositories complete in the order repo-1, repo-2, repo-0
+	repos := []config.Repository{
+		fakeRepository("repo-0", 80*time.Millisecond),
+		fakeRepository("repo-1", 0),
+		fakeRepository("repo-2", 40*time.Millisecond),
+	}
+
+	var out bytes.Buffer
+	results := scanRepositories(context.Background(), scanner.NewScanner(true), repos, len(repos), &out)
+
+	for i, result := range results {
+		if result.Name != repos[i].Name {
+			t.Errorf("results[%d] is %q, want %q", i, result.Name, repos[i].Name)
+		}
+		if result.Status != "UPDATE_AVAILABLE" || result.LatestVersion != "v1.1.0" || result.Attempts != 2 {
+			t.Errorf("results[%d] = %+v, want update to v1.1.0 after 2 attempts", i, result)
+		}
+	}
+
+	// Each repository's retry and filter messages are printed together
+	var want strings.Builder
+	for _, repo := range repos {
+		fmt.Fprintf(&want, "Attempt 1/2 for %s failed: connection reset (retrying in 10ms)\n", repo.Name)
+		fmt.Fprintf(&want, "Ignoring tag 'v2.0.0-rc.1' due to exclude pattern '-rc'\n")
+	}
+	if out.String() != want.String() {
+		t.Errorf("verbose output:\n%s\nwant:\n%s", out.String(), want.String())
+	}
+}
+
+// errorSource fails in the way named by a repository's URL.
+type errorSource struct{}
+
+func (errorSource) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	switch repo.URL {
+	case "deadline":
+		<-ctx.Done()
+		return nil, fmt.Errorf("listing tags: %w", ctx.Err())
+	case "killed":
+		// Like git ls-remote killed at the deadline
+		<-ctx.Done()
+		return nil, errors.New("git ls-remote failed: signal: killed")
+	case "client-timeout":
+		// Like an HTTP client timeout, which wraps a deadline error
+		return nil, fmt.Errorf("Get \"https://example.com\": %w", context.DeadlineExceeded)
+	default:
+		return nil, scanner.Permanent(errors.New("repository not found"))
+	}
+}
+
+func TestScanRepositoryTimeout(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"deadline", "TIMEOUT"},
+		{"killed", "TIMEOUT"},
+		{"client-timeout", "TIMEOUT"},
+		{"not-found", "ERROR"},
+	}
+
+	versionScanner := scanner.NewScanner(false)
+	for _, tt := range tests {
+		repo := &config.Repository{
+			Name:           tt.url,
+			Type:           "fake-error",
+			URL:            tt.url,
+			CurrentVersion: "1.0.0",
+			Timeout:        config.Duration(20 * time.Millisecond),
+		}
+		result := scanRepository(context.Background(), versionScanner, repo, io.Discard)
+		if result.Status != tt.want {
+			t.Errorf("%s: status = %s (%s), want %s", tt.url, result.Status, result.Error, tt.want)
+		}
+	}
+}
+
+func TestScanRepositoryScanDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	repo := &config.Repository{Name: "killed", Type: "fake-error", URL: "killed", CurrentVersion: "1.0.0"}
+	if result := scanRepository(ctx, scanner.NewScanner(false), repo, io.Discard); result.Status != "TIMEOUT" {
+		t.Errorf("status = %s (%s), want TIMEOUT", result.Status, result.Error)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	update := func(updateType version.UpdateType) output.ScanResult {
+		return output.ScanResult{Status: "UPDATE_AVAILABLE", UpdateType: string(updateType)}
+	}
+	upToDate := output.ScanResult{Status: "UP_TO_DATE"}
+
+	tests := []struct {
+		failOn  string
+		results []output.ScanResult
+		want    int
+	}{
+		{"", []output.ScanResult{upToDate}, 0},
+		{"", []output.ScanResult{upToDate, update(version.UpdatePrerelease)}, 1},
+		{"patch", []output.ScanResult{update(version.UpdateCalVerMicro)}, 1},
+		{"minor", []output.ScanResult{update(version.UpdatePatch)}, 0},
+		{"minor", []output.ScanResult{update(version.UpdatePatch), update(version.UpdateMinor)}, 1},
+		{"minor", []output.ScanResult{update(version.UpdateCalVerMonth)}, 1},
+		{"major", []output.ScanResult{update(version.UpdateMinor)}, 0},
+		{"major", []output.ScanResult{update(version.UpdateCalVerYear)}, 1},
+		// Updates of string versions are not classified and always count
+		{"major", []output.ScanResult{update(version.UpdateNone)}, 1},
+		{"major", []output.ScanResult{update(version.UpdatePatch), {Status: "TIMEOUT"}}, 3},
+		{"", []output.ScanResult{update(version.UpdateMajor), {Status: "ERROR"}}, 3},
+	}
+
+	for _, tt := range tests {
+		severity, err := failSeverity(tt.failOn)
+		if err != nil {
+			t.Fatalf("failSeverity(%q): %v", tt.failOn, err)
+		}
+		if got := exitCode(tt.results, severity); got != tt.want {
+			t.Errorf("--fail-on %q: exitCode(%+v) = %d, want %d", tt.failOn, tt.results, got, tt.want)
+		}
+	}
+
+	for _, failOn := range []string{"prerelease", "calver-year", "MAJOR"} {
+		if _, err := failSeverity(failOn); err == nil {
+			t.Errorf("failSeverity(%q) succeeded, want error", failOn)
+		}
+	}
+}
+
+func TestClassifyUpdate(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		versioning      *config.Versioning
+		want            version.UpdateType
+	}{
+		{"v1.2.3", "v2.0.0", &config.Versioning{IgnorePrefix: "v"}, version.UpdateMajor},
+		{"1.2.3", "1.2.4", nil, version.UpdatePatch},
+		{"helm-chart-4.2.1", "helm-chart-4.3.0", &config.Versioning{IncludePattern: `^helm-chart-(?P<version>.+)$`}, version.UpdateMinor},
+		{"2024.01.5", "2024.1.6", &config.Versioning{Scheme: "calver"}, version.UpdateCalVerMicro},
+		{"1:2.0-1", "2:1.0-1", &config.Versioning{Scheme: "debian"}, version.UpdateMajor},
+		{"a", "b", &config.Versioning{Scheme: "string"}, version.UpdateNone},
+	}
+	for _, tt := range tests {
+		if got := classifyUpdate(tt.current, tt.latest, tt.versioning); got != tt.want {
+			t.Errorf("classifyUpdate(%s, %s) = %q, want %q", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,16 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"regexp"
+	"time"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/version"
 )
 
 type Config struct {
+	Retry        *Retry       `json:"retry,omitempty"`
 	Repositories []Repository `json:"repositories"`
 }
 
@@ -15,18 +21,201 @@ type Repository struct {
 	URL            string      `json:"url"`
 	CurrentVersion string      `json:"currentVersion"`
 	Versioning     *Versioning `json:"versioning,omitempty"`
+	UpdatePolicy   string      `json:"updatePolicy,omitempty"`
 	Auth           *Auth       `json:"auth,omitempty"`
+	Timeout        Duration    `json:"timeout,omitempty"`
+	Retry          *Retry      `json:"retry,omitempty"`
+	Git            *Git        `json:"git,omitempty"`
+	GitHub         *GitHub     `json:"github,omitempty"`
+	GitLab         *GitLab     `json:"gitlab,omitempty"`
+	Helm           *Helm       `json:"helm,omitempty"`
+	NPM            *NPM        `json:"npm,omitempty"`
+	PyPI           *PyPI       `json:"pypi,omitempty"`
+	GoMod          *GoMod      `json:"gomod,omitempty"`
+	Maven          *Maven      `json:"maven,omitempty"`
+	Cargo          *Cargo      `json:"cargo,omitempty"`
+	APT            *APT        `json:"apt,omitempty"`
+	RPM            *RPM        `json:"rpm,omitempty"`
+	HTTP           *HTTP       `json:"http,omitempty"`
 }
 
 type Versioning struct {
-	Scheme        string   `json:"scheme,omitempty"`
-	IgnorePrefix  string   `json:"ignorePrefix,omitempty"`
+	Scheme         string   `json:"scheme,omitempty"`
+	IgnorePrefix   string   `json:"ignorePrefix,omitempty"`
 	IgnoreSuffixes []string `json:"ignoreSuffixes,omitempty"`
+	// IncludePattern is a regular expression tags must match. A capture
+	// group named "version" extracts the version from the tag, e.g.
+	// "^helm-chart-(?P<version>.+)$".
+	IncludePattern string `json:"includePattern,omitempty"`
+	// ExcludePatterns are regular expressions of tags to ignore.
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+}
+
+// Retry controls how often a transient scan failure is retried. Delays start
+// at InitialBackoff and double up to MaxBackoff; Jitter randomly shortens
+// each delay by up to the given fraction (0-1).
+type Retry struct {
+	MaxAttempts    int      `json:"maxAttempts,omitempty"`
+	InitialBackoff Duration `json:"initialBackoff,omitempty"`
+	MaxBackoff     Duration `json:"maxBackoff,omitempty"`
+	Jitter         float64  `json:"jitter,omitempty"`
+}
+
+// Git holds options for "git" repositories.
+type Git struct {
+	// Local reads the tags of a repository on disk (a clone or bare mirror
+	// at a local path or file:// URL) with git for-each-ref instead of
+	// git ls-remote, so no remote is contacted.
+	Local bool `json:"local,omitempty"`
+	// Transport selects how remotes are queried: "exec" runs git ls-remote,
+	// "native" speaks the git protocol in-process over HTTP(S) and SSH. The
+	// default is "exec", or "native" if the git binary is not installed.
+	Transport string `json:"transport,omitempty"`
+	// TagPattern is a glob such as "v1.*" or "release/*" restricting the
+	// tags considered. Its literal prefix is passed to the server, which
+	// then only sends matching refs.
+	TagPattern string `json:"tagPattern,omitempty"`
+}
+
+// GitHub holds options for "github-release" repositories.
+type GitHub struct {
+	// APIURL is the REST API base URL, e.g. https://ghe.example.com/api/v3.
+	// Defaults to https://api.github.com for github.com repositories and to
+	// <host>/api/v3 otherwise.
+	APIURL             string `json:"apiUrl,omitempty"`
+	IncludeDrafts      bool   `json:"includeDrafts,omitempty"`
+	ExcludePrereleases bool   `json:"excludePrereleases,omitempty"`
+}
+
+// GitLab holds options for "gitlab" repositories.
+type GitLab struct {
+	// APIURL is the v4 API base URL. Defaults to <host>/api/v4.
+	APIURL string `json:"apiUrl,omitempty"`
+	// Project is the project path ("group/subgroup/project") or numeric ID.
+	// Defaults to the path of the repository URL.
+	Project string `json:"project,omitempty"`
+	// Endpoint selects whether "tags" (default) or "releases" are listed.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// Helm holds options for "helm" repositories.
+type Helm struct {
+	// Chart is the chart name within the repository index. For OCI-hosted
+	// charts it defaults to the last path component of the URL.
+	Chart string `json:"chart,omitempty"`
+	// UseAppVersion reports the appVersion of the chart instead of the
+	// chart version. Not available for OCI-hosted charts.
+	UseAppVersion bool `json:"useAppVersion,omitempty"`
+}
+
+// NPM holds options for "npm" repositories, whose URL is the package name.
+type NPM struct {
+	// Registry is the registry base URL. Defaults to https://registry.npmjs.org.
+	Registry string `json:"registry,omitempty"`
+	// DistTag limits the candidates to the version a dist-tag such as
+	// "latest" or "next" points to. By default all versions are considered.
+	DistTag string `json:"distTag,omitempty"`
+}
+
+// PyPI holds options for "pypi" repositories, whose URL is the project name.
+type PyPI struct {
+	// API selects the "json" API (default) or the PEP 691 "simple" API,
+	// which most private indexes implement.
+	API string `json:"api,omitempty"`
+	// Index is the API base URL. Defaults to https://pypi.org/pypi for the
+	// JSON API and https://pypi.org/simple for the simple API.
+	Index string `json:"index,omitempty"`
+}
+
+// GoMod holds options for "gomod" repositories, whose URL is the module path.
+type GoMod struct {
+	// Proxy is a GOPROXY-style list such as "https://proxy.golang.org,direct".
+	// Defaults to $GOPROXY, or to "https://proxy.golang.org,direct".
+	Proxy string `json:"proxy,omitempty"`
+	// Private is a GOPRIVATE-style list of module path patterns that are
+	// always fetched directly from git. Defaults to $GONOPROXY, then $GOPRIVATE.
+	Private string `json:"private,omitempty"`
+	// GitURL is the repository to list tags from when fetching directly.
+	// Defaults to https://<module path without major version suffix>.
+	GitURL string `json:"gitUrl,omitempty"`
+}
+
+// Maven holds options for "maven" repositories, whose URL is the base URL
+// of the Maven repository.
+type Maven struct {
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+}
+
+// Cargo holds options for "cargo" repositories, whose URL is the crate name.
+type Cargo struct {
+	// Index is the sparse index URL of the registry. Defaults to
+	// https://index.crates.io.
+	Index string `json:"index,omitempty"`
+}
+
+// APT holds options for "apt" repositories, whose URL is the archive base
+// URL, e.g. http://deb.debian.org/debian.
+type APT struct {
+	Package   string `json:"package"`
+	Suite     string `json:"suite"`
+	Component string `json:"component,omitempty"` // defaults to "main"
+	Arch      string `json:"arch,omitempty"`      // defaults to "amd64"
+}
+
+// RPM holds options for "rpm" repositories, whose URL is the baseurl of a
+// YUM/DNF repository.
+type RPM struct {
+	Package string `json:"package"`
+	// Arch limits the packages considered to an architecture; "noarch"
+	// packages always match. By default all architectures are considered.
+	Arch string `json:"arch,omitempty"`
+}
+
+// HTTP holds options for "http" repositories, whose URL is fetched and
+// searched for versions with either JSONPath or Regex.
+type HTTP struct {
+	// Headers are sent with the request. The placeholder ${token} in a
+	// value is replaced by the token in Auth.EnvVariable.
+	Headers map[string]string `json:"headers,omitempty"`
+	// JSONPath selects the versions in a JSON response, e.g.
+	// "$.releases[*].version".
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Regex matches the versions in any response. The first capture group,
+	// or the group named "version", is the version.
+	Regex string `json:"regex,omitempty"`
 }
 
 type Auth struct {
 	Type        string `json:"type"`
 	EnvVariable string `json:"envVariable"`
+	// Username is sent along with the token where a registry requires
+	// basic authentication, e.g. Docker Hub.
+	Username string `json:"username,omitempty"`
+}
+
+// Duration is a time.Duration that is written in configuration files as a
+// Go duration string such as "30s" or "2m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s: must be a string such as \"30s\"", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	if parsed < 0 {
+		return fmt.Errorf("invalid duration %q: must not be negative", s)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
 }
 
 func LoadConfig(filepath string) (*Config, error) {
@@ -40,21 +229,90 @@ func LoadConfig(filepath string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := config.Retry.validate(); err != nil {
+		return nil, err
+	}
+
 	// Set default values
 	for i := range config.Repositories {
+		if config.Repositories[i].Retry == nil {
+			config.Repositories[i].Retry = config.Retry
+		} else if err := config.Repositories[i].Retry.validate(); err != nil {
+			return nil, fmt.Errorf("repository '%s': %w", config.Repositories[i].Name, err)
+		}
 		if config.Repositories[i].Versioning == nil {
-			config.Repositories[i].Versioning = &Versioning{
-				Scheme: "semver",
-			}
+			config.Repositories[i].Versioning = &Versioning{}
 		}
 		if config.Repositories[i].Versioning.Scheme == "" {
-			config.Repositories[i].Versioning.Scheme = "semver"
+			config.Repositories[i].Versioning.Scheme = defaultScheme(config.Repositories[i].Type)
+		}
+		if err := config.Repositories[i].Versioning.validate(); err != nil {
+			return nil, fmt.Errorf("repository '%s': %w", config.Repositories[i].Name, err)
+		}
+		if err := config.Repositories[i].validateUpdatePolicy(); err != nil {
+			return nil, fmt.Errorf("repository '%s': %w", config.Repositories[i].Name, err)
 		}
 	}
 
 	return &config, nil
 }
 
+// defaultSchemes are the versioning schemes of repository types whose
+// versions are not semver. Their versions are rejected or misordered
+// as semver, e.g. PyPI's 1.0.post1, or parse as semver pre-releases,
+// e.g. Debian's 2.36.1-8.
+var defaultSchemes = map[string]string{
+	"apt":   "debian",
+	"maven": "maven",
+	"pypi":  "pep440",
+	"rpm":   "rpm",
+}
+
+// defaultScheme returns the versioning scheme of repositories of repoType
+// that do not configure one.
+func defaultScheme(repoType string) string {
+	if scheme, ok := defaultSchemes[repoType]; ok {
+		return scheme
+	}
+	return "semver"
+}
+
+func (r *Retry) validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("retry maxAttempts must not be negative")
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+func (v *Versioning) validate() error {
+	if _, err := regexp.Compile(v.IncludePattern); err != nil {
+		return fmt.Errorf("invalid versioning includePattern: %w", err)
+	}
+	for _, pattern := range v.ExcludePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid versioning excludePatterns: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) validateUpdatePolicy() error {
+	if r.UpdatePolicy == "" {
+		return nil
+	}
+	if r.Versioning.Scheme == "string" {
+		return fmt.Errorf("updatePolicy is not supported by the string versioning scheme")
+	}
+	_, err := version.ParseUpdatePolicy(r.UpdatePolicy)
+	return err
+}
+
 func (c *Config) FindRepository(name string) *Repository {
 	for i := range c.Repositories {
 		if c.Repositories[i].Name == name {
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigDefaultScheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos.json")
+	err := os.WriteFile(path, []byte(`{"repositories": [
+		{"name": "git", "type": "git", "url": "https://example.com/repo.git", "currentVersion": "v1.0.0"},
+		{"name": "apt", "type": "apt", "url": "http://deb.debian.org/debian", "currentVersion": "2.36.1-8"},
+		{"name": "apt-semver", "type": "apt", "url": "http://deb.debian.org/debian", "currentVersion": "1.0.0", "versioning": {"scheme": "semver"}},
+		{"name": "rpm", "type": "rpm", "url": "https://example.com/el9", "currentVersion": "3.0.7-25.el9"},
+		{"name": "pypi", "type": "pypi", "url": "requests", "currentVersion": "2.31.0", "versioning": {"ignorePrefix": "v"}},
+		{"name": "maven", "type": "maven", "url": "", "currentVersion": "6.4.4.Final"}
+	]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"git":        "semver",
+		"apt":        "debian",
+		"apt-semver": "semver",
+		"rpm":        "rpm",
+		"pypi":       "pep440",
+		"maven":      "maven",
+	}
+	for name, scheme := range want {
+		repo := config.FindRepository(name)
+		if repo == nil {
+			t.Fatalf("repository %q not found", name)
+		}
+		if repo.Versioning.Scheme != scheme {
+			t.Errorf("repository %q has scheme %q, want %q", name, repo.Versioning.Scheme, scheme)
+		}
+	}
+}
+
+func TestLoadConfigUpdatePolicy(t *testing.T) {
+	tests := []struct {
+		repository string
+		wantErr    bool
+	}{
+		{`{"name": "pypi", "type": "pypi", "url": "requests", "currentVersion": "2.31.0", "updatePolicy": "minor"}`, false},
+		{`{"name": "maven", "type": "maven", "url": "", "currentVersion": "6.4.4.Final", "updatePolicy": "~6.4"}`, false},
+		{`{"name": "git", "type": "git", "url": "https://example.com/repo.git", "currentVersion": "v1.0.0", "updatePolicy": ">=1 <"}`, true},
+		{`{"name": "string", "type": "git", "url": "https://example.com/repo.git", "currentVersion": "a", "versioning": {"scheme": "string"}, "updatePolicy": "minor"}`, true},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "repos.json")
+		if err := os.WriteFile(path, []byte(`{"repositories": [`+tt.repository+`]}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadConfig(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadConfig(%s) error = %v, want error %v", tt.repository, err, tt.wantErr)
+		}
+	}
+}
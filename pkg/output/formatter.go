@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 )
 
 type ScanResult struct {
@@ -11,7 +12,14 @@ type ScanResult struct {
 	Status         string `json:"status"`
 	CurrentVersion string `json:"currentVersion"`
 	LatestVersion  string `json:"latestVersion,omitempty"`
-	Error          string `json:"error,omitempty"`
+	// UpdatePolicy and LatestInRange are set for repositories with an
+	// update policy, LatestInRange being the latest version it allows.
+	UpdatePolicy  string `json:"updatePolicy,omitempty"`
+	LatestInRange string `json:"latestInRange,omitempty"`
+	// UpdateType classifies available updates, e.g. "major" or "calver-year"
+	UpdateType string `json:"updateType,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
 }
 
 type JSONOutput struct {
@@ -20,12 +28,18 @@ type JSONOutput struct {
 }
 
 type Summary struct {
-	Total             int `json:"total"`
-	UpToDate          int `json:"upToDate"`
-	UpdatesAvailable  int `json:"updatesAvailable"`
-	Errors            int `json:"errors"`
+	Total            int `json:"total"`
+	UpToDate         int `json:"upToDate"`
+	UpdatesAvailable int `json:"updatesAvailable"`
+	Errors           int `json:"errors"`
+	Timeouts         int `json:"timeouts"`
+	// UpdateTypes counts the available updates by update type
+	UpdateTypes map[string]int `json:"updateTypes,omitempty"`
 }
 
+// updateTypeOrder is the order update types are listed in the summary.
+var updateTypeOrder = []string{"major", "calver-year", "minor", "calver-month", "patch", "calver-micro", "prerelease"}
+
 type Formatter struct {
 	jsonOutput bool
 	quiet      bool
@@ -72,27 +86,67 @@ func (f *Formatter) printHuman(results []ScanResult) {
 	for _, result := range results {
 		switch result.Status {
 		case "UP_TO_DATE":
-			if !f.quiet {
-				fmt.Printf("- %s: UP-TO-DATE (Current: %s)\n", result.Name, result.CurrentVersion)
+			if f.quiet {
+				continue
 			}
+			fmt.Printf("- %s: UP-TO-DATE (Current: %s%s)\n", result.Name, result.CurrentVersion, outsidePolicy(result))
 		case "UPDATE_AVAILABLE":
-			fmt.Printf("- %s: NEW VERSION FOUND! (Current: %s -> Latest: %s)\n", 
-				result.Name, result.CurrentVersion, result.LatestVersion)
+			latest := result.LatestVersion
+			if result.UpdatePolicy != "" {
+				latest = result.LatestInRange
+			}
+			updateType := ""
+			if result.UpdateType != "" {
+				updateType = fmt.Sprintf(" [%s]", result.UpdateType)
+			}
+			fmt.Printf("- %s: NEW VERSION FOUND! (Current: %s -> Latest: %s%s)%s\n",
+				result.Name, result.CurrentVersion, latest, outsidePolicy(result), updateType)
 		case "ERROR":
 			fmt.Printf("- %s: ERROR! (%s)\n", result.Name, result.Error)
+		case "TIMEOUT":
+			fmt.Printf("- %s: TIMEOUT! (%s)\n", result.Name, result.Error)
+		}
+		if f.verbose && result.Attempts > 0 {
+			fmt.Printf("  Attempts: %d\n", result.Attempts)
 		}
 	}
 	
 	// Print summary
 	if !f.quiet {
-		fmt.Printf("\nScan finished. Updates available for %d repository(ies).", summary.UpdatesAvailable)
+		fmt.Printf("\nScan finished. Updates available for %d repository(ies)%s.", summary.UpdatesAvailable, updateTypeCounts(summary))
 		if summary.Errors > 0 {
 			fmt.Printf(" %d error(s) occurred.", summary.Errors)
 		}
+		if summary.Timeouts > 0 {
+			fmt.Printf(" %d repository(ies) timed out.", summary.Timeouts)
+		}
 		fmt.Println()
 	}
 }
 
+// outsidePolicy notes a newer version excluded by the update policy.
+func outsidePolicy(result ScanResult) string {
+	if result.UpdatePolicy == "" || result.LatestVersion == result.LatestInRange || result.LatestVersion == result.CurrentVersion {
+		return ""
+	}
+	return fmt.Sprintf("; %s outside update policy '%s'", result.LatestVersion, result.UpdatePolicy)
+}
+
+// updateTypeCounts lists the number of updates of each type, e.g.
+// " (1 major, 2 patch)".
+func updateTypeCounts(summary Summary) string {
+	var counts []string
+	for _, updateType := range updateTypeOrder {
+		if n := summary.UpdateTypes[updateType]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, updateType))
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(counts, ", ") + ")"
+}
+
 func (f *Formatter) calculateSummary(results []ScanResult) Summary {
 	summary := Summary{
 		Total: len(results),
@@ -104,8 +158,16 @@ func (f *Formatter) calculateSummary(results []ScanResult) Summary {
 			summary.UpToDate++
 		case "UPDATE_AVAILABLE":
 			summary.UpdatesAvailable++
+			if result.UpdateType != "" {
+				if summary.UpdateTypes == nil {
+					summary.UpdateTypes = make(map[string]int)
+				}
+				summary.UpdateTypes[result.UpdateType]++
+			}
 		case "ERROR":
 			summary.Errors++
+		case "TIMEOUT":
+			summary.Timeouts++
 		}
 	}
 	
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+)
+
+func TestCalculateSummary(t *testing.T) {
+	results := []ScanResult{
+		{Name: "a", Status: "UPDATE_AVAILABLE", UpdateType: "major"},
+		{Name: "b", Status: "UPDATE_AVAILABLE", UpdateType: "patch"},
+		{Name: "c", Status: "UPDATE_AVAILABLE", UpdateType: "major"},
+		{Name: "d", Status: "UPDATE_AVAILABLE", UpdateType: "calver-month"},
+		// Updates of string versions have no type
+		{Name: "e", Status: "UPDATE_AVAILABLE"},
+		{Name: "f", Status: "UP_TO_DATE"},
+		{Name: "g", Status: "ERROR", Error: "repository not found"},
+		{Name: "h", Status: "TIMEOUT", Error: "context deadline exceeded"},
+	}
+
+	summary := NewFormatter(false, false, false).calculateSummary(results)
+	if summary.Total != 8 || summary.UpdatesAvailable != 5 || summary.UpToDate != 1 || summary.Errors != 1 || summary.Timeouts != 1 {
+		t.Errorf("calculateSummary() = %+v", summary)
+	}
+	want := map[string]int{"major": 2, "patch": 1, "calver-month": 1}
+	if !maps.Equal(summary.UpdateTypes, want) {
+		t.Errorf("UpdateTypes = %v, want %v", summary.UpdateTypes, want)
+	}
+	if got, want := updateTypeCounts(summary), " (2 major, 1 calver-month, 1 patch)"; got != want {
+		t.Errorf("updateTypeCounts() = %q, want %q", got, want)
+	}
+}
+
+func TestSummaryWithoutUpdates(t *testing.T) {
+	summary := NewFormatter(true, false, false).calculateSummary([]ScanResult{{Name: "a", Status: "UP_TO_DATE"}})
+	if got := updateTypeCounts(summary); got != "" {
+		t.Errorf("updateTypeCounts() = %q, want none", got)
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"total":1,"upToDate":1,"updatesAvailable":0,"errors":0,"timeouts":0}`
+	if string(data) != want {
+		t.Errorf("summary JSON = %s, want %s", data, want)
+	}
+}
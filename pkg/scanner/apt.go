@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// APTScanner lists the versions of a package in the Packages index of a
+// Debian or Ubuntu archive.
+type APTScanner struct {
+	verbose bool
+}
+
+func init() {
+	RegisterSource("apt", func(verbose bool) Source {
+		return NewAPTScanner(verbose)
+	})
+}
+
+func NewAPTScanner(verbose bool) *APTScanner {
+	return &APTScanner{verbose: verbose}
+}
+
+func (a *APTScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.APT
+	if options == nil || options.Package == "" || options.Suite == "" {
+		return nil, Permanent(fmt.Errorf("no package configured: set apt.package and apt.suite"))
+	}
+
+	component := options.Component
+	if component == "" {
+		component = "main"
+	}
+	arch := options.Arch
+	if arch == "" {
+		arch = "amd64"
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	indexURL := fmt.Sprintf("%s/dists/%s/%s/binary-%s/Packages",
+		strings.TrimSuffix(repo.URL, "/"), options.Suite, component, arch)
+
+	index, err := a.fetchIndex(ctx, indexURL, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download package index: %w", err)
+	}
+
+	versions := parsePackagesIndex(index, options.Package)
+	if len(versions) == 0 {
+		return nil, Permanent(fmt.Errorf("package '%s' not found in %s", options.Package, indexURL))
+	}
+	return versions, nil
+}
+
+// fetchIndex downloads Packages.gz, falling back to the uncompressed
+// Packages file which some repositories publish instead.
+func (a *APTScanner) fetchIndex(ctx context.Context, indexURL string, header http.Header) ([]byte, error) {
+	body, err := httpGetBody(ctx, indexURL+".gz", header, a.verbose)
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return httpGetBody(ctx, indexURL, header, a.verbose)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s.gz: %w", indexURL, err)
+	}
+	defer reader.Close()
+
+	index, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s.gz: %w", indexURL, err)
+	}
+	return index, nil
+}
+
+// parsePackagesIndex returns the distinct versions of the stanzas for
+// name in a Debian control file.
+func parsePackagesIndex(index []byte, name string) []string {
+	var versions []string
+	seen := map[string]bool{}
+
+	var pkg, ver string
+	flush := func() {
+		if pkg == name && ver != "" && !seen[ver] {
+			seen[ver] = true
+			versions = append(versions, ver)
+		}
+		pkg, ver = "", ""
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(index))
+	lines.Buffer(nil, 1024*1024)
+	for lines.Scan() {
+		line := lines.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if field, value, ok := strings.Cut(line, ":"); ok && !strings.HasPrefix(line, " ") {
+			switch field {
+			case "Package":
+				pkg = strings.TrimSpace(value)
+			case "Version":
+				ver = strings.TrimSpace(value)
+			}
+		}
+	}
+	flush()
+
+	return versions
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const packagesIndex = `Package: openssl
+Version: 3.0.11-1~deb12u2
+Architecture: amd64
+Description: Secure Sockets Layer toolkit
+ Version: 9.9.9
+ continuation lines are not fields
+
+Package: libssl3
+Version: 3.0.11-1~deb12u2
+
+Package: openssl
+Architecture: amd64
+Version: 3.0.13-1~deb12u1
+
+Package: openssl
+Version: 3.0.11-1~deb12u2
+Architecture: arm64
+
+Package: openssl
+Version: 1:3.1.0-1
+`
+
+func TestParsePackagesIndex(t *testing.T) {
+	got := parsePackagesIndex([]byte(packagesIndex), "openssl")
+	want := []string{"3.0.11-1~deb12u2", "3.0.13-1~deb12u1", "1:3.1.0-1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parsePackagesIndex() = %v, want %v", got, want)
+	}
+
+	if got := parsePackagesIndex([]byte(packagesIndex), "missing"); len(got) != 0 {
+		t.Errorf("parsePackagesIndex() of a missing package = %v, want none", got)
+	}
+}
+
+func TestAPT(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(packagesIndex))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/debian/dists/bookworm/main/binary-amd64/Packages.gz":
+			w.Write(compressed.Bytes())
+		case "/debian/dists/plain/contrib/binary-arm64/Packages":
+			w.Write([]byte(packagesIndex))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		options config.APT
+	}{
+		{"gzip", config.APT{Package: "openssl", Suite: "bookworm"}},
+		{"uncompressed", config.APT{Package: "openssl", Suite: "plain", Component: "contrib", Arch: "arm64"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{Type: "apt", URL: server.URL + "/debian/", APT: &tt.options}
+			versions, err := NewAPTScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"3.0.11-1~deb12u2", "3.0.13-1~deb12u1", "1:3.1.0-1"}; !slices.Equal(versions, want) {
+				t.Errorf("ListVersions() = %v, want %v", versions, want)
+			}
+
+			// The debian scheme orders epochs and revisions
+			repo.Versioning = &config.Versioning{Scheme: "debian"}
+			result, err := NewScanner(false).Scan(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.LatestVersion != "1:3.1.0-1" {
+				t.Errorf("Scan() latest version = %q, want %q", result.LatestVersion, "1:3.1.0-1")
+			}
+		})
+	}
+
+	repo := &config.Repository{Type: "apt", URL: server.URL + "/debian", APT: &config.APT{Package: "missing", Suite: "bookworm"}}
+	if _, err := NewAPTScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() of a missing package error = %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const defaultCargoIndex = "https://index.crates.io"
+
+// CargoScanner lists the versions of a Rust crate from a registry
+// implementing the sparse index protocol, skipping yanked versions.
+type CargoScanner struct {
+	verbose bool
+}
+
+type cargoIndexEntry struct {
+	Vers   string `json:"vers"`
+	Yanked bool   `json:"yanked"`
+}
+
+func init() {
+	RegisterSource("cargo", func(verbose bool) Source {
+		return NewCargoScanner(verbose)
+	})
+}
+
+func NewCargoScanner(verbose bool) *CargoScanner {
+	return &CargoScanner{verbose: verbose}
+}
+
+func (c *CargoScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	name := strings.ToLower(strings.TrimSpace(repo.URL))
+	if name == "" {
+		return nil, Permanent(fmt.Errorf("no crate configured: set url to the crate name"))
+	}
+
+	index := defaultCargoIndex
+	if repo.Cargo != nil && repo.Cargo.Index != "" {
+		// Cargo configuration spells sparse registries as sparse+https://...
+		index = strings.TrimPrefix(repo.Cargo.Index, "sparse+")
+	}
+	index = strings.TrimSuffix(index, "/")
+
+	header := http.Header{}
+	token, err := authToken(repo)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		if repo.Auth.Type != "" && repo.Auth.Type != "token" {
+			return nil, Permanent(fmt.Errorf("unsupported authentication type for cargo repositories: %s", repo.Auth.Type))
+		}
+		// Registries expect the token as is, without an auth scheme
+		header.Set("Authorization", token)
+	}
+
+	body, err := httpGetBody(ctx, index+"/"+cargoIndexPath(name), header, c.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch crate index: %w", err)
+	}
+
+	var versions []string
+	lines := bufio.NewScanner(bytes.NewReader(body))
+	lines.Buffer(nil, 1024*1024)
+	for lines.Scan() {
+		line := bytes.TrimSpace(lines.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry cargoIndexEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse crate index entry: %w", err)
+		}
+		if entry.Yanked {
+			if c.verbose {
+				logf(ctx, "Ignoring yanked version '%s'\n", entry.Vers)
+			}
+			continue
+		}
+		versions = append(versions, entry.Vers)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read crate index: %w", err)
+	}
+
+	return versions, nil
+}
+
+// cargoIndexPath returns the location of a crate's file within the index:
+// 1/a, 2/ab, 3/a/abc and ab/cd/abcd... for longer names.
+func cargoIndexPath(name string) string {
+	switch len(name) {
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestCargoIndexPath(t *testing.T) {
+	tests := map[string]string{
+		"a":     "1/a",
+		"ab":    "2/ab",
+		"abc":   "3/a/abc",
+		"serde": "se/rd/serde",
+	}
+	for name, want := range tests {
+		if got := cargoIndexPath(name); got != want {
+			t.Errorf("cargoIndexPath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCargo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index/se/rd/serde" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "cio-token" {
+			t.Errorf("Authorization = %q, want %q", got, "cio-token")
+		}
+		fmt.Fprint(w, `{"name":"serde","vers":"1.0.0","deps":[],"cksum":"x","features":{},"yanked":false}
+{"name":"serde","vers":"1.0.1","deps":[],"cksum":"x","features":{},"yanked":true}
+
+{"name":"serde","vers":"1.0.2","deps":[],"cksum":"x","features":{},"yanked":false}
+`)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_CARGO_TOKEN", "cio-token")
+
+	repo := &config.Repository{
+		Type:  "cargo",
+		URL:   "Serde",
+		Auth:  &config.Auth{Type: "token", EnvVariable: "TEST_CARGO_TOKEN"},
+		Cargo: &config.Cargo{Index: "sparse+" + server.URL + "/index/"},
+	}
+	versions, err := NewCargoScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1.0.0", "1.0.2"}; !slices.Equal(versions, want) {
+		t.Errorf("ListVersions() = %v, want %v", versions, want)
+	}
+}
@@ -1,219 +1,465 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"os/exec"
-	"sort"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/wellcom-rocks/updates-sucks/pkg/config"
-	"github.com/wellcom-rocks/updates-sucks/pkg/version"
 )
 
+const gitWaitDelay = time.Second
+
 type GitScanner struct {
 	verbose bool
 }
 
+func init() {
+	RegisterSource("git", func(verbose bool) Source {
+		return NewGitScanner(verbose)
+	})
+}
+
 func NewGitScanner(verbose bool) *GitScanner {
 	return &GitScanner{verbose: verbose}
 }
 
-func (g *GitScanner) GetLatestVersion(repo *config.Repository) (string, error) {
-	if repo.Type != "git" {
-		return "", fmt.Errorf("unsupported repository type: %s", repo.Type)
+func (g *GitScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.Git
+	if options == nil {
+		options = &config.Git{}
 	}
 
-	// Prepare git command with authentication
-	cmd := exec.Command("git", "ls-remote", "--tags", "--refs", repo.URL)
+	if options.TagPattern != "" {
+		if _, err := path.Match(options.TagPattern, ""); err != nil {
+			return nil, Permanent(fmt.Errorf("invalid git.tagPattern %q: %w", options.TagPattern, err))
+		}
+	}
 
-	// Configure authentication if needed
-	if repo.Auth != nil && repo.Auth.EnvVariable != "" {
-		token := os.Getenv(repo.Auth.EnvVariable)
-		if token == "" {
-			return "", fmt.Errorf("authentication token not found in environment variable %s", repo.Auth.EnvVariable)
-		}
-
-		// Configure git authentication based on auth type
-		switch repo.Auth.Type {
-		case "token":
-			// For GitHub/GitLab tokens, modify the URL to include authentication
-			authenticatedURL := g.addTokenToURL(repo.URL, token)
-			cmd.Args[len(cmd.Args)-1] = authenticatedURL
-		case "ssh":
-			// For SSH authentication, the token should be an SSH key path
-			cmd.Env = append(os.Environ(), fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o StrictHostKeyChecking=no", token))
+	tags, err := g.listTags(ctx, repo, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers only filter by prefix, and git ls-remote matches patterns
+	// against the tail of refs, so apply the glob to the full tag name
+	return filterTagPattern(tags, options.TagPattern), nil
+}
+
+func (g *GitScanner) listTags(ctx context.Context, repo *config.Repository, options *config.Git) ([]string, error) {
+	if options.Local {
+		return g.listLocal(ctx, repo, options.TagPattern)
+	}
+
+	switch defaultTransport(options.Transport) {
+	case "native":
+		prefixes := []string{"refs/tags/" + globPrefix(options.TagPattern)}
+		if strings.HasPrefix(repo.URL, "https://") || strings.HasPrefix(repo.URL, "http://") {
+			refs, err := g.listHTTP(ctx, repo, prefixes)
+			if err != nil {
+				return nil, err
+			}
+			return tagsFromRefs(refs), nil
+		}
+		if remote, ok := parseSSHRemote(repo.URL); ok {
+			refs, err := g.listSSH(ctx, repo, remote, prefixes)
+			if err != nil {
+				return nil, err
+			}
+			return tagsFromRefs(refs), nil
+		}
+		// Local paths and git:// remotes are left to the git binary
+		return g.lsRemote(ctx, repo, options.TagPattern)
+	case "exec":
+		return g.lsRemote(ctx, repo, options.TagPattern)
+	default:
+		return nil, Permanent(fmt.Errorf("unsupported git transport: %s", options.Transport))
+	}
+}
+
+// defaultTransport returns transport, or if it is empty "exec" where the git
+// binary is installed, as git ls-remote honours ~/.ssh/config, credential
+// helpers and url.*.insteadOf, and "native" otherwise.
+func defaultTransport(transport string) string {
+	if transport != "" {
+		return transport
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return "native"
+	}
+	return "exec"
+}
+
+// globPrefix returns the literal part of a glob before its first
+// metacharacter, with escapes removed, which servers can filter on.
+func globPrefix(pattern string) string {
+	var prefix strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*', '?', '[':
+			return prefix.String()
+		case '\\':
+			if i+1 == len(pattern) {
+				return prefix.String()
+			}
+			i++
+			prefix.WriteByte(pattern[i])
 		default:
-			return "", fmt.Errorf("unsupported authentication type: %s", repo.Auth.Type)
+			prefix.WriteByte(c)
 		}
 	}
+	return prefix.String()
+}
+
+// filterTagPattern keeps the tags matching the glob pattern, or all tags if
+// pattern is empty.
+func filterTagPattern(tags []string, pattern string) []string {
+	if pattern == "" {
+		return tags
+	}
+	pattern = matchPattern(pattern)
+	var matching []string
+	for _, tag := range tags {
+		if matched, _ := path.Match(pattern, tag); matched {
+			matching = append(matching, tag)
+		}
+	}
+	return matching
+}
+
+// matchPattern rewrites the glob negation "[!...]" that git uses to the
+// "[^...]" that path.Match understands.
+func matchPattern(pattern string) string {
+	b := []byte(pattern)
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\\':
+			i++
+		case b[i] == '[' && i+1 < len(b) && b[i+1] == '!':
+			b[i+1] = '^'
+		}
+	}
+	return string(b)
+}
+
+// lsRemote lists the tags of a remote by running git ls-remote, passing
+// pattern on so that git can have the server filter the refs.
+func (g *GitScanner) lsRemote(ctx context.Context, repo *config.Repository, pattern string) ([]string, error) {
+	// Prepare git command with authentication
+	args := []string{"ls-remote", "--tags", "--refs", repo.URL}
+	if pattern != "" {
+		args = append(args, "refs/tags/"+pattern)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// git hands the transport to helper processes (git-remote-https, ssh)
+	// that inherit our stdout and survive the kill on cancellation, so do
+	// not wait on the pipe indefinitely once the context is done.
+	cmd.WaitDelay = gitWaitDelay
+
+	// Configure authentication if needed
+	remoteURL, env, err := g.remoteAuth(repo)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args[4] = remoteURL
+	cmd.Env = env
 
 	if g.verbose {
-		fmt.Printf("Executing: git ls-remote --tags --refs %s\n", repo.URL)
+		logf(ctx, "Executing: git %s\n", strings.Join(args, " "))
 	}
 
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to execute git ls-remote: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("git ls-remote did not finish: %w", ctx.Err())
+		}
+		return nil, g.classifyError("ls-remote", err)
 	}
 
-	// Parse tags from output
-	tags := g.parseTags(string(output))
-	if len(tags) == 0 {
-		return "", fmt.Errorf("no tags found in repository")
+	return g.parseTags(string(output)), nil
+}
+
+// remoteAuth returns the URL and environment with which git reaches the
+// repository using its configured authentication; local repositories have
+// none, and a nil environment is the process's own.
+func (g *GitScanner) remoteAuth(repo *config.Repository) (string, []string, error) {
+	if repo.Auth == nil || repo.Auth.EnvVariable == "" || isLocalGitURL(repo.URL) {
+		return repo.URL, nil, nil
+	}
+	token := os.Getenv(repo.Auth.EnvVariable)
+	if token == "" {
+		return "", nil, Permanent(fmt.Errorf("authentication token not found in environment variable %s", repo.Auth.EnvVariable))
 	}
 
-	// Remove prefix if configured
-	if repo.Versioning != nil && repo.Versioning.IgnorePrefix != "" {
-		tags = g.removePrefix(tags, repo.Versioning.IgnorePrefix)
+	// Configure git authentication based on auth type
+	switch repo.Auth.Type {
+	case "token":
+		// For GitHub/GitLab tokens, modify the URL to include authentication
+		return g.addTokenToURL(repo.URL, token), nil, nil
+	case "ssh":
+		// For SSH authentication, the token should be an SSH key path
+		return repo.URL, append(os.Environ(), fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o StrictHostKeyChecking=no", token)), nil
+	default:
+		return "", nil, Permanent(fmt.Errorf("unsupported authentication type: %s", repo.Auth.Type))
 	}
+}
 
-	// Filter and sort tags based on versioning scheme first
-	scheme := "semver"
-	if repo.Versioning != nil && repo.Versioning.Scheme != "" {
-		scheme = repo.Versioning.Scheme
+// hasFile reports whether the tree of a tag contains the named file. Tags
+// of remote repositories are fetched without history into a temporary
+// repository first, which requires the git binary.
+func (g *GitScanner) hasFile(ctx context.Context, repo *config.Repository, tag, name string) (bool, error) {
+	dir := ""
+	rev := "refs/tags/" + tag
+	if isLocalGitURL(repo.URL) {
+		dir = localGitPath(repo.URL)
+	} else {
+		remoteURL, env, err := g.remoteAuth(repo)
+		if err != nil {
+			return false, err
+		}
+		if dir, err = os.MkdirTemp("", "updates-sucks-"); err != nil {
+			return false, err
+		}
+		defer os.RemoveAll(dir)
+
+		if _, err := g.runGit(ctx, env, "", "init", "--quiet", "--bare", dir); err != nil {
+			return false, err
+		}
+		if g.verbose {
+			logf(ctx, "Executing: git fetch --depth=1 %s %s\n", repo.URL, rev)
+		}
+		if _, err := g.runGit(ctx, env, dir, "fetch", "--quiet", "--depth=1", "--no-tags", remoteURL, rev); err != nil {
+			return false, err
+		}
+		rev = "FETCH_HEAD"
 	}
 
-	// Filter valid tags first, then apply suffix filtering
-	validTags := g.getValidTags(tags, scheme)
-	
-	// Filter out tags with ignored suffixes if configured
-	if repo.Versioning != nil && len(repo.Versioning.IgnoreSuffixes) > 0 {
-		validTags = g.filterSuffixes(validTags, repo.Versioning.IgnoreSuffixes)
+	output, err := g.runGit(ctx, nil, dir, "ls-tree", "--name-only", rev, "--", name)
+	if err != nil {
+		return false, err
 	}
+	return strings.TrimSpace(output) != "", nil
+}
 
-	latestTag, err := g.findLatestVersionFromValidTags(validTags, scheme)
+// runGit runs a git command in dir, or the working directory if dir is
+// empty, and returns its output, classifying failures like lsRemote does.
+func (g *GitScanner) runGit(ctx context.Context, env []string, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.WaitDelay = gitWaitDelay
+	cmd.Env = env
+	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to find latest version: %w", err)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s did not finish: %w", args[0], ctx.Err())
+		}
+		return "", g.classifyError(args[0], err)
+	}
+	return string(output), nil
+}
+
+// permanentGitErrors are fragments of git's stderr that indicate a failure
+// retrying will not fix.
+var permanentGitErrors = []string{
+	"authentication failed",
+	"could not read username",
+	"could not read password",
+	"terminal prompts disabled",
+	"permission denied",
+	"repository not found",
+	"does not appear to be a git repository",
+	"the project you were looking for could not be found",
+	"returned error: 401",
+	"returned error: 403",
+	"returned error: 404",
+	"host key verification failed",
+	"not a git repository",
+	"cannot change to",
+}
+
+// classifyError turns a failed git command into an error carrying git's
+// stderr, marked permanent when the cause cannot be fixed by retrying.
+func (g *GitScanner) classifyError(command string, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		// git could not be started at all
+		return Permanent(fmt.Errorf("failed to execute git %s: %w", command, err))
 	}
 
-	// Add prefix back if it was removed
-	if repo.Versioning != nil && repo.Versioning.IgnorePrefix != "" {
-		latestTag = repo.Versioning.IgnorePrefix + latestTag
+	stderr := strings.TrimSpace(string(exitErr.Stderr))
+	if stderr == "" {
+		return fmt.Errorf("failed to execute git %s: %w", command, err)
 	}
 
-	return latestTag, nil
+	wrapped := fmt.Errorf("failed to execute git %s: %w: %s", command, err, stderr)
+	if isPermanentGitMessage(stderr) {
+		return Permanent(wrapped)
+	}
+	return wrapped
 }
 
-func (g *GitScanner) parseTags(output string) []string {
-	var tags []string
-	lines := strings.Split(output, "\n")
+// missingRepositoryRegex matches git's message for a repository an HTTP
+// server answered with 404, e.g. "fatal: repository 'https://...' not found".
+var missingRepositoryRegex = regexp.MustCompile(`repository '[^']*' not found`)
 
-	for _, line := range lines {
-		if line == "" {
-			continue
+func isPermanentGitMessage(message string) bool {
+	lower := strings.ToLower(message)
+	if missingRepositoryRegex.MatchString(lower) {
+		return true
+	}
+	for _, fragment := range permanentGitErrors {
+		if strings.Contains(lower, fragment) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Format: <commit-hash>\trefs/tags/<tag-name>
-		parts := strings.Split(line, "\t")
-		if len(parts) != 2 {
-			continue
+// wireError classifies an error talking to a git server in-process: errors
+// reported by the server are permanent where retrying will not help, as
+// with classifyError.
+func (g *GitScanner) wireError(ctx context.Context, remote string, err error) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("listing refs of %s did not finish: %w", remote, ctx.Err())
+	}
+	var protocolErr *GitProtocolError
+	if errors.As(err, &protocolErr) {
+		if isPermanentGitMessage(protocolErr.Message) {
+			return Permanent(err)
 		}
+		return err
+	}
+	return fmt.Errorf("failed to read refs from %s: %w", remote, err)
+}
 
-		ref := parts[1]
-		if strings.HasPrefix(ref, "refs/tags/") {
-			tag := strings.TrimPrefix(ref, "refs/tags/")
-			tags = append(tags, tag)
-		}
+// listLocal reads the tags of a repository on disk with git for-each-ref.
+// Annotated tags are dereferenced to the object they tag, and tags that do
+// not point at a commit (e.g. tags of trees or keys) are skipped.
+func (g *GitScanner) listLocal(ctx context.Context, repo *config.Repository, pattern string) ([]string, error) {
+	if !isLocalGitURL(repo.URL) {
+		return nil, Permanent(fmt.Errorf("git.local requires a local path or file:// URL, got %s", repo.URL))
 	}
+	dir := localGitPath(repo.URL)
 
-	return tags
-}
+	// for-each-ref matches literal patterns only up to a slash, so pass it
+	// the glob itself
+	refPattern := "refs/tags/"
+	if pattern != "" {
+		refPattern += pattern
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "for-each-ref", "--format="+localTagFormat, refPattern)
+	cmd.WaitDelay = gitWaitDelay
 
-func (g *GitScanner) removePrefix(tags []string, prefix string) []string {
-	var result []string
-	for _, tag := range tags {
-		if strings.HasPrefix(tag, prefix) {
-			result = append(result, strings.TrimPrefix(tag, prefix))
+	if g.verbose {
+		logf(ctx, "Executing: git -C %s for-each-ref %s\n", dir, refPattern)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("git for-each-ref did not finish: %w", ctx.Err())
 		}
+		return nil, g.classifyError("for-each-ref", err)
 	}
-	return result
+
+	return g.parseLocalTags(ctx, string(output)), nil
 }
 
-func (g *GitScanner) filterSuffixes(tags []string, ignoreSuffixes []string) []string {
-	var result []string
-	for _, tag := range tags {
-		shouldIgnore := false
-		for _, suffix := range ignoreSuffixes {
-			if strings.Contains(tag, suffix) {
-				shouldIgnore = true
-				if g.verbose {
-					fmt.Printf("Ignoring tag '%s' due to suffix '%s'\n", tag, suffix)
-				}
-				break
+// localTagFormat prints the tag name, the type of the tagged object (after
+// dereferencing annotated tags) and the tagger date, or for lightweight
+// tags the committer date.
+const localTagFormat = "%(refname:strip=2)%09%(objecttype)%09%(*objecttype)%09%(creatordate:iso-strict)"
+
+func (g *GitScanner) parseLocalTags(ctx context.Context, output string) []string {
+	var tags []string
+	for _, line := range strings.Split(output, "\n") {
+		// Format: <tag-name>\t<type>\t<dereferenced type>\t<date>
+		parts := strings.Split(line, "\t")
+		if len(parts) != 4 {
+			continue
+		}
+		tag, objectType, targetType, date := parts[0], parts[1], parts[2], parts[3]
+
+		annotated := objectType == "tag"
+		if annotated {
+			objectType = targetType
+		}
+		if objectType != "commit" {
+			if g.verbose {
+				logf(ctx, "Ignoring tag '%s' of a %s\n", tag, objectType)
 			}
+			continue
 		}
-		if !shouldIgnore {
-			result = append(result, tag)
+
+		if g.verbose {
+			kind := "lightweight"
+			if annotated {
+				kind = "annotated"
+			}
+			logf(ctx, "Found %s tag '%s' (%s)\n", kind, tag, date)
 		}
+		tags = append(tags, tag)
 	}
-	return result
+	return tags
 }
 
-func (g *GitScanner) getValidTags(tags []string, scheme string) []string {
-	switch scheme {
-	case "semver":
-		return version.FilterValidSemVer(tags)
-	case "calver":
-		return version.FilterValidCalVer(tags)
-	case "string":
-		return tags // All tags are valid for string comparison
-	default:
-		return tags
+// isLocalGitURL reports whether a repository URL refers to a repository on
+// disk rather than a remote.
+func isLocalGitURL(repoURL string) bool {
+	if strings.HasPrefix(repoURL, "file://") {
+		return true
 	}
-}
-
-func (g *GitScanner) findLatestVersionFromValidTags(validTags []string, scheme string) (string, error) {
-	if len(validTags) == 0 {
-		return "", fmt.Errorf("no valid tags found after filtering")
-	}
-	
-	switch scheme {
-	case "semver":
-		sorted := version.SortSemVer(validTags)
-		return sorted[len(sorted)-1], nil
-	case "calver":
-		sorted := version.SortCalVer(validTags)
-		return sorted[len(sorted)-1], nil
-	case "string":
-		sorted := make([]string, len(validTags))
-		copy(sorted, validTags)
-		sort.Strings(sorted)
-		return sorted[len(sorted)-1], nil
-	default:
-		return "", fmt.Errorf("unsupported versioning scheme: %s", scheme)
+	if strings.Contains(repoURL, "://") {
+		return false
+	}
+	if filepath.IsAbs(repoURL) || strings.HasPrefix(repoURL, ".") {
+		return true
 	}
+	// Anything else that exists on disk, as git ls-remote does before
+	// considering scp-style host:path URLs
+	info, err := os.Stat(repoURL)
+	return err == nil && info.IsDir()
 }
 
-func (g *GitScanner) findLatestVersion(tags []string, scheme string) (string, error) {
-	switch scheme {
-	case "semver":
-		return g.findLatestSemVer(tags)
-	case "calver":
-		return g.findLatestCalVer(tags)
-	case "string":
-		return g.findLatestString(tags)
-	default:
-		return "", fmt.Errorf("unsupported versioning scheme: %s", scheme)
+// localGitPath returns the directory of a local repository URL.
+func localGitPath(repoURL string) string {
+	if u, err := url.Parse(repoURL); err == nil && u.Scheme == "file" {
+		return u.Path
 	}
+	return repoURL
 }
 
-func (g *GitScanner) findLatestSemVer(tags []string) (string, error) {
-	// Import the version package functions to properly sort semver tags
-	return findLatestVersionFromTags(tags, "semver")
-}
+func (g *GitScanner) parseTags(output string) []string {
+	var tags []string
+	lines := strings.Split(output, "\n")
 
-func (g *GitScanner) findLatestCalVer(tags []string) (string, error) {
-	return findLatestVersionFromTags(tags, "calver")
-}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
 
-func (g *GitScanner) findLatestString(tags []string) (string, error) {
-	return findLatestVersionFromTags(tags, "string")
-}
+		// Format: <commit-hash>\trefs/tags/<tag-name>
+		parts := strings.Split(line, "\t")
+		if len(parts) != 2 {
+			continue
+		}
 
-func findLatestVersionFromTags(tags []string, scheme string) (string, error) {
-	return version.GetLatestVersion(tags, scheme)
+		ref := parts[1]
+		if strings.HasPrefix(ref, "refs/tags/") {
+			tag := strings.TrimPrefix(ref, "refs/tags/")
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
 }
 
 func (g *GitScanner) addTokenToURL(repoURL, token string) string {
@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// newFixtureRepo creates a repository with a lightweight tag, an annotated
+// tag and a tag of a tree, whose version is the highest.
+func newFixtureRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := fixtureGit(t, dir)
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "-m", "initial")
+	git("tag", "v1.0.0")
+	git("tag", "--annotate", "-m", "release 1.1.0", "v1.1.0")
+	git("tag", "v9.0.0", "HEAD^{tree}")
+	return dir
+}
+
+// fixtureGit returns a function running git in dir, isolated from the
+// user's configuration.
+func fixtureGit(t *testing.T, dir string) func(args ...string) {
+	return func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(cmd.Environ(),
+			"GIT_CONFIG_GLOBAL=/dev/null",
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+}
+
+func TestGitLocal(t *testing.T) {
+	dir := newFixtureRepo(t)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"path", dir},
+		{"file URL", "file://" + filepath.ToSlash(dir)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{
+				Name: "fixture",
+				Type: "git",
+				URL:  tt.url,
+				Git:  &config.Git{Local: true},
+				Versioning: &config.Versioning{
+					Scheme:       "semver",
+					IgnorePrefix: "v",
+				},
+			}
+
+			tags, err := NewGitScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(tags)
+			if want := []string{"v1.0.0", "v1.1.0"}; !slices.Equal(tags, want) {
+				t.Errorf("ListVersions() = %v, want %v", tags, want)
+			}
+
+			result, err := NewScanner(false).Scan(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.LatestVersion != "v1.1.0" {
+				t.Errorf("Scan() latest version = %q, want %q", result.LatestVersion, "v1.1.0")
+			}
+		})
+	}
+}
+
+func TestGitLocalTagPattern(t *testing.T) {
+	dir := newFixtureRepo(t)
+
+	repo := &config.Repository{
+		Type: "git",
+		URL:  dir,
+		Git:  &config.Git{Local: true, TagPattern: "v1.0.*"},
+	}
+	tags, err := NewGitScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.0.0"}; !slices.Equal(tags, want) {
+		t.Errorf("ListVersions() = %v, want %v", tags, want)
+	}
+}
+
+func TestGlobPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"", ""},
+		{"v1.2.3", "v1.2.3"},
+		{"v1.*", "v1."},
+		{"v1.?.0", "v1."},
+		{"v[12].*", "v"},
+		{"*-stable", ""},
+		{`release\*`, "release*"},
+		{`v1\.2.*`, "v1.2."},
+		{`v1\[x]*`, "v1[x]"},
+		{`v1\`, "v1"},
+	}
+	for _, tt := range tests {
+		if got := globPrefix(tt.pattern); got != tt.want {
+			t.Errorf("globPrefix(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFilterTagPattern(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.1.0", "v2.0.0", "release*", "release-1", "app/v1.0.0"}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"", tags},
+		{"v1.*", []string{"v1.0.0", "v1.1.0"}},
+		{"v?.0.0", []string{"v1.0.0", "v2.0.0"}},
+		{"v[2-9].*", []string{"v2.0.0"}},
+		{"v1.[!0].0", []string{"v1.1.0"}},
+		{"v1.[^0].0", []string{"v1.1.0"}},
+		{`release\*`, []string{"release*"}},
+		{"release*", []string{"release*", "release-1"}},
+		// As for path.Match, * does not match a slash
+		{"*", []string{"v1.0.0", "v1.1.0", "v2.0.0", "release*", "release-1"}},
+		{"app/*", []string{"app/v1.0.0"}},
+		{"v3.*", nil},
+	}
+	for _, tt := range tests {
+		if got := filterTagPattern(tags, tt.pattern); !slices.Equal(got, tt.want) {
+			t.Errorf("filterTagPattern(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestGitLocalRejectsRemote(t *testing.T) {
+	repo := &config.Repository{
+		Type: "git",
+		URL:  "https://example.com/repo.git",
+		Git:  &config.Git{Local: true},
+	}
+	_, err := NewGitScanner(false).ListVersions(context.Background(), repo)
+	if err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() error = %v, want a permanent error", err)
+	}
+}
+
+func TestIsPermanentGitMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"remote: Repository not found.\nfatal: repository 'https://github.com/o/r.git/' not found", true},
+		{"fatal: repository 'https://example.com/r.git/' not found", true},
+		{"fatal: 'o/r.git' does not appear to be a git repository", true},
+		{"remote: The project you were looking for could not be found or you don't have permission to view it.", true},
+		{"fatal: Authentication failed for 'https://example.com/r.git/'", true},
+		{"fatal: unable to access 'https://example.com/r.git/': The requested URL returned error: 404", true},
+		{"fatal: unable to access 'https://example.com/r.git/': Could not resolve host: example.com (Host not found)", false},
+		{"fatal: unable to access 'https://example.com/r.git/': Received HTTP code 404 from proxy after CONNECT", false},
+		{"fatal: unable to access 'https://example.com/r.git/': The requested URL returned error: 502", false},
+		{"ssh: connect to host example.com port 22: Connection timed out", false},
+	}
+	for _, tt := range tests {
+		if got := isPermanentGitMessage(tt.message); got != tt.want {
+			t.Errorf("isPermanentGitMessage(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
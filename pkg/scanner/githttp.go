@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const gitUploadPackAdvertisement = "application/x-git-upload-pack-advertisement"
+
+// listHTTP lists the refs of an HTTP(S) remote matching prefixes. It uses
+// the ls-refs command of protocol v2 where the server supports it, so that
+// only the requested refs are sent, and otherwise reads the ref
+// advertisement of the smart or dumb HTTP protocol.
+func (g *GitScanner) listHTTP(ctx context.Context, repo *config.Repository, prefixes []string) ([]string, error) {
+	remote, header, err := g.httpRemote(repo)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("Git-Protocol", "version=2")
+
+	resp, err := httpGet(ctx, remote+"/info/refs?service=git-upload-pack", header, g.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), gitUploadPackAdvertisement) {
+		if g.verbose {
+			logf(ctx, "Server does not speak the smart HTTP protocol, reading info/refs\n")
+		}
+		return g.readDumbRefs(ctx, remote, resp.Body)
+	}
+
+	adv, err := readAdvertisement(newPktReader(remote, resp.Body))
+	if err != nil {
+		return nil, g.wireError(ctx, remote, err)
+	}
+	if !adv.v2 {
+		if g.verbose {
+			logf(ctx, "Server does not support protocol v2, using the ref advertisement\n")
+		}
+		return adv.refs, nil
+	}
+	if _, ok := adv.capability("ls-refs"); !ok {
+		return nil, Permanent(&GitProtocolError{URL: remote, Message: "server does not support ls-refs"})
+	}
+
+	if g.verbose {
+		logf(ctx, "Listing refs with protocol v2 (ref-prefix %s)\n", strings.Join(prefixes, ", "))
+	}
+	header.Set("Content-Type", "application/x-git-upload-pack-request")
+	header.Set("Accept", "application/x-git-upload-pack-result")
+	resp, err = httpPost(ctx, remote+"/git-upload-pack", header, strings.NewReader(lsRefsRequest(adv, prefixes)), g.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	refs, err := readLsRefs(newPktReader(remote, resp.Body))
+	if err != nil {
+		return nil, g.wireError(ctx, remote, err)
+	}
+	return refs, nil
+}
+
+// httpRemote returns the repository URL without credentials, and the
+// request headers authenticating as the credentials in the URL or in
+// repo.Auth, the latter sent the way addTokenToURL would embed them.
+func (g *GitScanner) httpRemote(repo *config.Repository) (string, http.Header, error) {
+	rawURL := repo.URL
+	if repo.Auth != nil && repo.Auth.EnvVariable != "" {
+		token, err := authToken(repo)
+		if err != nil {
+			return "", nil, err
+		}
+		if repo.Auth.Type != "token" {
+			return "", nil, Permanent(fmt.Errorf("unsupported authentication type for HTTP remotes: %s", repo.Auth.Type))
+		}
+		rawURL = g.addTokenToURL(rawURL, token)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, Permanent(fmt.Errorf("invalid repository URL: %w", err))
+	}
+
+	header := http.Header{}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := u.User.Username() + ":" + password
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		u.User = nil
+	}
+	return strings.TrimSuffix(u.String(), "/"), header, nil
+}
+
+// readDumbRefs parses the info/refs file served by dumb HTTP remotes, which
+// lists one "<oid>\t<refname>" per line.
+func (g *GitScanner) readDumbRefs(ctx context.Context, remote string, body io.Reader) ([]string, error) {
+	var refs []string
+	lines := bufio.NewScanner(body)
+	for lines.Scan() {
+		_, ref, ok := strings.Cut(lines.Text(), "\t")
+		if !ok {
+			return nil, Permanent(&GitProtocolError{URL: remote, Message: "not a git repository (invalid info/refs)"})
+		}
+		refs = append(refs, ref)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, g.wireError(ctx, remote, err)
+	}
+	return refs, nil
+}
@@ -0,0 +1,256 @@
+package scanner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// newGitHTTPServer serves a repository with tags v1.0.0, v1.1.0 and v2.0.0
+// over the smart HTTP protocol v2 at /v2.git, the smart protocol v0 at
+// /v0.git and the dumb protocol at /dumb.git.
+func newGitHTTPServer(t *testing.T) *httptest.Server {
+	refs := []string{"refs/heads/main", "refs/tags/v1.0.0", "refs/tags/v1.1.0", "refs/tags/v1.1.0^{}", "refs/tags/v2.0.0"}
+	const oid = "1111111111111111111111111111111111111111"
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v2.git/info/refs":
+			if r.Header.Get("Git-Protocol") != "version=2" {
+				t.Errorf("Git-Protocol = %q, want version=2", r.Header.Get("Git-Protocol"))
+			}
+			w.Header().Set("Content-Type", gitUploadPackAdvertisement)
+			fmt.Fprint(w, pktLine("# service=git-upload-pack\n")+"0000"+pktLine("version 2\n")+pktLine("ls-refs\n")+"0000")
+
+		case "POST /v2.git/git-upload-pack":
+			body, _ := io.ReadAll(r.Body)
+			var prefixes []string
+			for _, line := range strings.Split(string(body), "\n") {
+				if _, prefix, ok := strings.Cut(line, "ref-prefix "); ok {
+					prefixes = append(prefixes, prefix)
+				}
+			}
+			if !strings.Contains(string(body), "command=ls-refs") || len(prefixes) == 0 {
+				t.Errorf("unexpected upload-pack request %q", body)
+			}
+			for _, ref := range refs {
+				for _, prefix := range prefixes {
+					if strings.HasPrefix(ref, prefix) && !strings.HasSuffix(ref, "^{}") {
+						fmt.Fprint(w, pktLine(oid+" "+ref+"\n"))
+						break
+					}
+				}
+			}
+			fmt.Fprint(w, "0000")
+
+		case "GET /v0.git/info/refs":
+			w.Header().Set("Content-Type", gitUploadPackAdvertisement)
+			fmt.Fprint(w, pktLine("# service=git-upload-pack\n")+"0000")
+			for i, ref := range refs {
+				if i == 0 {
+					ref += "\x00multi_ack"
+				}
+				fmt.Fprint(w, pktLine(oid+" "+ref+"\n"))
+			}
+			fmt.Fprint(w, "0000")
+
+		case "GET /dumb.git/info/refs":
+			w.Header().Set("Content-Type", "text/plain")
+			for _, ref := range refs {
+				fmt.Fprintf(w, "%s\t%s\n", oid, ref)
+			}
+
+		case "GET /err.git/info/refs":
+			w.Header().Set("Content-Type", gitUploadPackAdvertisement)
+			fmt.Fprint(w, pktLine("ERR Repository not found\n"))
+
+		case "GET /unavailable.git/info/refs":
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestGitNativeHTTP(t *testing.T) {
+	server := newGitHTTPServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		tagPattern string
+		want       []string
+	}{
+		{"/v2.git", "", []string{"v1.0.0", "v1.1.0", "v2.0.0"}},
+		{"/v2.git", "v1.*", []string{"v1.0.0", "v1.1.0"}},
+		{"/v0.git", "", []string{"v1.0.0", "v1.1.0", "v2.0.0"}},
+		{"/v0.git", "v2*", []string{"v2.0.0"}},
+		{"/dumb.git", "", []string{"v1.0.0", "v1.1.0", "v2.0.0"}},
+		// Servers filter on the prefix before the first metacharacter, the
+		// rest of the pattern is matched client-side
+		{"/v2.git", "v1.?.0", []string{"v1.0.0", "v1.1.0"}},
+		{"/v2.git", "*.0.0", []string{"v1.0.0", "v2.0.0"}},
+		{"/v2.git", `v1\.1*`, []string{"v1.1.0"}},
+		{"/v0.git", "v[12].0.0", []string{"v1.0.0", "v2.0.0"}},
+		{"/dumb.git", "v1.[!0].0", []string{"v1.1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.tagPattern, func(t *testing.T) {
+			repo := &config.Repository{
+				Type: "git",
+				URL:  server.URL + tt.path,
+				Git:  &config.Git{Transport: "native", TagPattern: tt.tagPattern},
+			}
+			tags, err := NewGitScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tags, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitNativeHTTPErrors(t *testing.T) {
+	server := newGitHTTPServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		path      string
+		transient bool
+	}{
+		{"/err.git", false},
+		{"/missing.git", false},
+		{"/unavailable.git", true},
+	}
+
+	for _, tt := range tests {
+		repo := &config.Repository{
+			Type: "git",
+			URL:  server.URL + tt.path,
+			Git:  &config.Git{Transport: "native"},
+		}
+		_, err := NewGitScanner(false).ListVersions(context.Background(), repo)
+		if err == nil {
+			t.Errorf("ListVersions() of %s succeeded, want error", tt.path)
+			continue
+		}
+		if IsTransient(err) != tt.transient {
+			t.Errorf("ListVersions() of %s: IsTransient(%v) = %v, want %v", tt.path, err, !tt.transient, tt.transient)
+		}
+	}
+}
+
+func TestParseSSHRemote(t *testing.T) {
+	tests := []struct {
+		url  string
+		want *sshRemote
+	}{
+		{"git@github.com:owner/repo.git", &sshRemote{user: "git", host: "github.com", path: "owner/repo.git"}},
+		{"ssh://git@example.com:2222/srv/repo.git", &sshRemote{user: "git", host: "example.com", port: "2222", path: "/srv/repo.git"}},
+		{"ssh://example.com/~alice/repo.git", &sshRemote{host: "example.com", path: "~alice/repo.git"}},
+		{"[::1]:repo.git", &sshRemote{host: "::1", path: "repo.git"}},
+		{"https://example.com/repo.git", nil},
+		{"./dir:with-colon", nil},
+		{"/srv/repo.git", nil},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseSSHRemote(tt.url)
+		if ok != (tt.want != nil) {
+			t.Errorf("parseSSHRemote(%q) ok = %v, want %v", tt.url, ok, tt.want != nil)
+			continue
+		}
+		if ok && *got != *tt.want {
+			t.Errorf("parseSSHRemote(%q) = %+v, want %+v", tt.url, *got, *tt.want)
+		}
+	}
+}
+
+func TestKnownHostKeyAlgorithms(t *testing.T) {
+	newKey := func(key any) ssh.PublicKey {
+		t.Helper()
+		public, err := ssh.NewPublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return public
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	lines := []string{
+		knownhosts.Line([]string{"example.com"}, newKey(&rsaKey.PublicKey)),
+		knownhosts.Line([]string{knownhosts.HashHostname("example.com")}, newKey(&ecdsaKey.PublicKey)),
+		knownhosts.Line([]string{"[example.com]:2222"}, newKey(ed25519Key)),
+	}
+	if err := os.WriteFile(knownHosts, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	callback, err := knownhosts.New(knownHosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addr string
+		want []string
+	}{
+		{"example.com:22", []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA, ssh.KeyAlgoECDSA256}},
+		{"example.com:2222", []string{ssh.KeyAlgoED25519}},
+		{"other.example.com:22", nil},
+	}
+	for _, tt := range tests {
+		got := knownHostKeyAlgorithms(callback, tt.addr)
+		slices.Sort(got)
+		slices.Sort(tt.want)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("knownHostKeyAlgorithms(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultTransport(t *testing.T) {
+	if got := defaultTransport("native"); got != "native" {
+		t.Errorf(`defaultTransport("native") = %q, want "native"`, got)
+	}
+	if got := defaultTransport("exec"); got != "exec" {
+		t.Errorf(`defaultTransport("exec") = %q, want "exec"`, got)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if got := defaultTransport(""); got != "native" {
+		t.Errorf(`defaultTransport("") without git = %q, want "native"`, got)
+	}
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const (
+	defaultGitHubURL    = "https://github.com"
+	defaultGitHubAPIURL = "https://api.github.com"
+)
+
+// GitHubReleaseScanner lists the tag names of a repository's GitHub
+// releases, which unlike its git tags do not include nightly or tooling
+// tags.
+type GitHubReleaseScanner struct {
+	verbose bool
+}
+
+type gitHubRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+func init() {
+	RegisterSource("github-release", func(verbose bool) Source {
+		return NewGitHubReleaseScanner(verbose)
+	})
+}
+
+func NewGitHubReleaseScanner(verbose bool) *GitHubReleaseScanner {
+	return &GitHubReleaseScanner{verbose: verbose}
+}
+
+func (g *GitHubReleaseScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	baseURL, path, err := splitRepoURL(repo.URL, defaultGitHubURL)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Count(path, "/") != 1 {
+		return nil, Permanent(fmt.Errorf("invalid GitHub repository %q: expected owner/repo", path))
+	}
+
+	options := repo.GitHub
+	if options == nil {
+		options = &config.GitHub{}
+	}
+
+	apiURL := strings.TrimSuffix(options.APIURL, "/")
+	if apiURL == "" {
+		if baseURL == defaultGitHubURL {
+			apiURL = defaultGitHubAPIURL
+		} else {
+			// GitHub Enterprise Server
+			apiURL = baseURL + "/api/v3"
+		}
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	var tags []string
+	next := fmt.Sprintf("%s/repos/%s/releases?per_page=100", apiURL, path)
+	for next != "" {
+		var releases []gitHubRelease
+		respHeader, err := httpGetJSON(ctx, next, header, g.verbose, &releases)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitHub releases: %w", err)
+		}
+
+		for _, release := range releases {
+			if release.Draft && !options.IncludeDrafts {
+				continue
+			}
+			if release.Prerelease && options.ExcludePrereleases {
+				if g.verbose {
+					logf(ctx, "Ignoring prerelease '%s'\n", release.TagName)
+				}
+				continue
+			}
+			tags = append(tags, release.TagName)
+		}
+
+		next, err = nextPage(next, respHeader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
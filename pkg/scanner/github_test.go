@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestGitHubReleases(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"tag_name": "v1.0.0"}, {"tag_name": "v0.9.0", "draft": true}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/releases?per_page=100&page=2>; rel="next", <%[1]s/repos/owner/repo/releases?per_page=100&page=2>; rel="last"`, server.URL))
+		fmt.Fprint(w, `[{"tag_name": "v2.0.0-rc.1", "prerelease": true}, {"tag_name": "v1.1.0"}]`)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+
+	tests := []struct {
+		name    string
+		options config.GitHub
+		want    []string
+	}{
+		{"default", config.GitHub{}, []string{"v2.0.0-rc.1", "v1.1.0", "v1.0.0"}},
+		{"exclude prereleases", config.GitHub{ExcludePrereleases: true}, []string{"v1.1.0", "v1.0.0"}},
+		{"include drafts", config.GitHub{IncludeDrafts: true}, []string{"v2.0.0-rc.1", "v1.1.0", "v1.0.0", "v0.9.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.APIURL = server.URL
+			repo := &config.Repository{
+				Type:   "github-release",
+				URL:    "https://github.com/owner/repo.git",
+				Auth:   &config.Auth{Type: "token", EnvVariable: "TEST_GITHUB_TOKEN"},
+				GitHub: &options,
+			}
+
+			tags, err := NewGitHubReleaseScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tags, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubReleasesErrors(t *testing.T) {
+	tests := []struct {
+		status       int
+		wantAttempts int
+	}{
+		{http.StatusNotFound, 1},
+		{http.StatusBadGateway, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			repo := &config.Repository{
+				Name:   "repo",
+				Type:   "github-release",
+				URL:    "owner/repo",
+				GitHub: &config.GitHub{APIURL: server.URL},
+				Retry:  &config.Retry{MaxAttempts: 3, InitialBackoff: config.Duration(time.Millisecond)},
+			}
+
+			result, err := NewScanner(false).Scan(context.Background(), repo)
+			if err == nil {
+				t.Fatal("Scan() succeeded, want an error")
+			}
+			if transient := tt.status >= 500; IsTransient(err) != transient {
+				t.Errorf("IsTransient(%v) = %v, want %v", err, !transient, transient)
+			}
+			if result.Attempts != tt.wantAttempts || requests != tt.wantAttempts {
+				t.Errorf("Scan() made %d attempts and %d requests, want %d", result.Attempts, requests, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestGitHubReleasesForeignNextLink(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request to foreign host with Authorization %q", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer foreign.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/releases?page=2>; rel="next"`, foreign.URL))
+		fmt.Fprint(w, `[{"tag_name": "v1.0.0"}]`)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+
+	repo := &config.Repository{
+		Type:   "github-release",
+		URL:    "owner/repo",
+		Auth:   &config.Auth{Type: "token", EnvVariable: "TEST_GITHUB_TOKEN"},
+		GitHub: &config.GitHub{APIURL: server.URL},
+	}
+	if _, err := NewGitHubReleaseScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() error = %v, want a permanent error", err)
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		current string
+		link    string
+		want    string
+		wantErr bool
+	}{
+		{"https://api.example.com/tags?page=1", "", "", false},
+		{"https://api.example.com/tags?page=1", `<https://api.example.com/tags?page=2>; rel="next"`, "https://api.example.com/tags?page=2", false},
+		{"https://registry.example.com/v2/app/tags/list", `</v2/app/tags/list?last=1.0>; rel="next"`, "https://registry.example.com/v2/app/tags/list?last=1.0", false},
+		{"https://api.example.com/tags?page=1", `<https://evil.example.com/tags?page=2>; rel="next"`, "", true},
+		{"https://api.example.com/tags?page=1", `<http://api.example.com/tags?page=2>; rel="next"`, "", true},
+		{"https://api.example.com/tags?page=1", `<https://api.example.com:8443/tags?page=2>; rel="next"`, "", true},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.link != "" {
+			header.Set("Link", tt.link)
+		}
+		got, err := nextPage(tt.current, header)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("nextPage(%q, %q) = %q, %v, want %q, error %v", tt.current, tt.link, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+// GitLabScanner lists tags or releases through the GitLab v4 API, for
+// instances that do not allow git over HTTPS for bots.
+type GitLabScanner struct {
+	verbose bool
+}
+
+type gitLabTag struct {
+	Name string `json:"name"`
+}
+
+type gitLabRelease struct {
+	TagName         string `json:"tag_name"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+}
+
+func init() {
+	RegisterSource("gitlab", func(verbose bool) Source {
+		return NewGitLabScanner(verbose)
+	})
+}
+
+func NewGitLabScanner(verbose bool) *GitLabScanner {
+	return &GitLabScanner{verbose: verbose}
+}
+
+func (g *GitLabScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.GitLab
+	if options == nil {
+		options = &config.GitLab{}
+	}
+
+	baseURL, project, err := splitRepoURL(repo.URL, defaultGitLabURL)
+	if err != nil {
+		return nil, err
+	}
+	if options.Project != "" {
+		project = trimURLPath(options.Project)
+	}
+	if project == "" {
+		return nil, Permanent(fmt.Errorf("no GitLab project configured: set a project URL or gitlab.project"))
+	}
+
+	apiURL := strings.TrimSuffix(options.APIURL, "/")
+	if apiURL == "" {
+		apiURL = baseURL + "/api/v4"
+	}
+
+	header, err := g.authHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Project paths are passed as a single URL-encoded path segment
+	projectID := strings.ReplaceAll(url.PathEscape(project), "/", "%2F")
+
+	switch options.Endpoint {
+	case "", "tags":
+		var tags []string
+		next := fmt.Sprintf("%s/projects/%s/repository/tags?per_page=100", apiURL, projectID)
+		for next != "" {
+			var page []gitLabTag
+			respHeader, err := httpGetJSON(ctx, next, header, g.verbose, &page)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list GitLab tags: %w", err)
+			}
+			for _, tag := range page {
+				tags = append(tags, tag.Name)
+			}
+			next, err = nextPage(next, respHeader)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return tags, nil
+
+	case "releases":
+		var tags []string
+		next := fmt.Sprintf("%s/projects/%s/releases?per_page=100", apiURL, projectID)
+		for next != "" {
+			var page []gitLabRelease
+			respHeader, err := httpGetJSON(ctx, next, header, g.verbose, &page)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list GitLab releases: %w", err)
+			}
+			for _, release := range page {
+				if release.UpcomingRelease {
+					if g.verbose {
+						logf(ctx, "Ignoring upcoming release '%s'\n", release.TagName)
+					}
+					continue
+				}
+				tags = append(tags, release.TagName)
+			}
+			next, err = nextPage(next, respHeader)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return tags, nil
+
+	default:
+		return nil, Permanent(fmt.Errorf("unsupported GitLab endpoint: %s", options.Endpoint))
+	}
+}
+
+// authHeader returns the PRIVATE-TOKEN header GitLab expects for personal,
+// project and group access tokens.
+func (g *GitLabScanner) authHeader(repo *config.Repository) (http.Header, error) {
+	header := http.Header{}
+	token, err := authToken(repo)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return header, nil
+	}
+	if repo.Auth.Type != "" && repo.Auth.Type != "token" {
+		return nil, Permanent(fmt.Errorf("unsupported authentication type for gitlab repositories: %s", repo.Auth.Type))
+	}
+	header.Set("PRIVATE-TOKEN", token)
+	return header, nil
+}
@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestGitLab(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want %q", got, "secret")
+		}
+
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/tags":
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `[{"name": "v1.0.0"}]`)
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/projects/group%%2Fsub%%2Fproject/repository/tags?per_page=100&page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"name": "v1.1.0"}]`)
+		case "/api/v4/projects/group%2Fsub%2Fproject/releases":
+			fmt.Fprint(w, `[{"tag_name": "v1.2.0", "upcoming_release": true}, {"tag_name": "v1.1.0"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GITLAB_TOKEN", "secret")
+
+	tests := []struct {
+		endpoint string
+		want     []string
+	}{
+		{"", []string{"v1.1.0", "v1.0.0"}},
+		{"releases", []string{"v1.1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			repo := &config.Repository{
+				Type:   "gitlab",
+				URL:    server.URL + "/group/sub/project.git",
+				Auth:   &config.Auth{Type: "token", EnvVariable: "TEST_GITLAB_TOKEN"},
+				GitLab: &config.GitLab{Endpoint: tt.endpoint},
+			}
+
+			tags, err := NewGitLabScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(tags, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitLabForeignNextLink(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request to foreign host with PRIVATE-TOKEN %q", r.Header.Get("PRIVATE-TOKEN"))
+		fmt.Fprint(w, `[]`)
+	}))
+	defer foreign.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/projects/group%%2Fproject/repository/tags?page=2>; rel="next"`, foreign.URL))
+		fmt.Fprint(w, `[{"name": "v1.0.0"}]`)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GITLAB_TOKEN", "secret")
+
+	repo := &config.Repository{
+		Type: "gitlab",
+		URL:  server.URL + "/group/project.git",
+		Auth: &config.Auth{Type: "token", EnvVariable: "TEST_GITLAB_TOKEN"},
+	}
+	if _, err := NewGitLabScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() error = %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,225 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// This file implements the parts of the git wire protocol needed to list the
+// tags of a remote: the reference advertisement of protocol v0/v1 and the
+// ls-refs command of protocol v2, both framed as pkt-lines.
+
+// GitProtocolError is returned when a git server reports an error, e.g. in
+// an ERR packet or on stderr, or when its response cannot be understood.
+type GitProtocolError struct {
+	URL     string
+	Message string
+}
+
+func (e *GitProtocolError) Error() string {
+	return fmt.Sprintf("git %s: %s", e.URL, e.Message)
+}
+
+type pktType int
+
+const (
+	pktData pktType = iota
+	pktFlush
+	pktDelim
+	pktResponseEnd
+)
+
+// maxPktLen is the largest pkt-line allowed, including the length prefix.
+const maxPktLen = 65520
+
+type pktReader struct {
+	url string
+	r   *bufio.Reader
+}
+
+func newPktReader(url string, r io.Reader) *pktReader {
+	return &pktReader{url: url, r: bufio.NewReader(r)}
+}
+
+// next reads a pkt-line and returns its payload without the trailing LF.
+// ERR packets are returned as a *GitProtocolError.
+func (p *pktReader) next() ([]byte, pktType, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(p.r, prefix[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+
+	length, err := strconv.ParseUint(string(prefix[:]), 16, 16)
+	if err != nil {
+		return nil, 0, p.protocolError("invalid pkt-line length %q", prefix[:])
+	}
+	switch length {
+	case 0:
+		return nil, pktFlush, nil
+	case 1:
+		return nil, pktDelim, nil
+	case 2:
+		return nil, pktResponseEnd, nil
+	case 3:
+		return nil, 0, p.protocolError("invalid pkt-line length %q", prefix[:])
+	}
+	if length > maxPktLen {
+		return nil, 0, p.protocolError("pkt-line too long (%d bytes)", length)
+	}
+
+	payload := make([]byte, length-4)
+	if _, err := io.ReadFull(p.r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	payload = bytes.TrimSuffix(payload, []byte("\n"))
+
+	if message, ok := bytes.CutPrefix(payload, []byte("ERR ")); ok {
+		return nil, 0, &GitProtocolError{URL: p.url, Message: string(message)}
+	}
+	return payload, pktData, nil
+}
+
+func (p *pktReader) protocolError(format string, args ...any) error {
+	return &GitProtocolError{URL: p.url, Message: fmt.Sprintf(format, args...)}
+}
+
+func pktLine(payload string) string {
+	return fmt.Sprintf("%04x%s", len(payload)+4, payload)
+}
+
+// gitAdvertisement is what a server sends when a client connects: the
+// capabilities of protocol v2, or the references of protocol v0/v1.
+type gitAdvertisement struct {
+	v2           bool
+	capabilities []string
+	refs         []string
+}
+
+func (a *gitAdvertisement) capability(name string) (string, bool) {
+	for _, c := range a.capabilities {
+		if c == name {
+			return "", true
+		}
+		if value, ok := strings.CutPrefix(c, name+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// readAdvertisement reads the server's advertisement up to the terminating
+// flush-pkt. The "# service=" header sent by smart HTTP servers is skipped.
+func readAdvertisement(p *pktReader) (*gitAdvertisement, error) {
+	adv := &gitAdvertisement{}
+	first := true
+	for {
+		payload, typ, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if typ == pktFlush {
+			if first {
+				// end of the "# service=" header, or an empty repository
+				// announced by a bare flush
+				continue
+			}
+			return adv, nil
+		}
+		if typ != pktData {
+			return nil, p.protocolError("unexpected packet in reference advertisement")
+		}
+
+		line := string(payload)
+		switch {
+		case first && strings.HasPrefix(line, "# service="):
+			continue
+		case first && line == "version 2":
+			adv.v2 = true
+		case first && line == "version 1":
+		case adv.v2:
+			adv.capabilities = append(adv.capabilities, line)
+		default:
+			// <oid> SP <refname> [NUL <capabilities>] for the first line
+			ref, caps, hasCaps := strings.Cut(line, "\x00")
+			if hasCaps {
+				adv.capabilities = strings.Fields(caps)
+			}
+			_, name, ok := strings.Cut(ref, " ")
+			if !ok {
+				return nil, p.protocolError("invalid reference advertisement %q", line)
+			}
+			if name != "capabilities^{}" {
+				adv.refs = append(adv.refs, name)
+			}
+		}
+		first = false
+	}
+}
+
+// lsRefsRequest returns a protocol v2 ls-refs command limited to refs
+// starting with one of prefixes.
+func lsRefsRequest(adv *gitAdvertisement, prefixes []string) string {
+	var b strings.Builder
+	b.WriteString(pktLine("command=ls-refs\n"))
+	b.WriteString(pktLine("agent=" + userAgent + "\n"))
+	if format, ok := adv.capability("object-format"); ok {
+		b.WriteString(pktLine("object-format=" + format + "\n"))
+	}
+	b.WriteString("0001")
+	for _, prefix := range prefixes {
+		b.WriteString(pktLine("ref-prefix " + prefix + "\n"))
+	}
+	b.WriteString("0000")
+	return b.String()
+}
+
+// readLsRefs reads the response to an ls-refs command: one
+// "<oid> <refname> [attributes]" line per ref, terminated by a flush-pkt.
+func readLsRefs(p *pktReader) ([]string, error) {
+	var refs []string
+	for {
+		payload, typ, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case pktFlush:
+			return refs, nil
+		case pktData:
+			fields := strings.Fields(string(payload))
+			if len(fields) < 2 {
+				return nil, p.protocolError("invalid ls-refs response %q", payload)
+			}
+			refs = append(refs, fields[1])
+		default:
+			return nil, p.protocolError("unexpected packet in ls-refs response")
+		}
+	}
+}
+
+// tagsFromRefs returns the tag names of refs, leaving out other refs and
+// the peeled "^{}" entries of annotated tags.
+func tagsFromRefs(refs []string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		tag, ok := strings.CutPrefix(ref, "refs/tags/")
+		if !ok || strings.HasSuffix(tag, "^{}") || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
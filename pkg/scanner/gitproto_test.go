@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPktReader(t *testing.T) {
+	input := pktLine("first\n") + "0000" + "0001" + "0002" + pktLine("no newline") + pktLine("ERR access denied\n")
+	p := newPktReader("https://example.com/repo.git", strings.NewReader(input))
+
+	want := []struct {
+		payload string
+		typ     pktType
+	}{
+		{"first", pktData},
+		{"", pktFlush},
+		{"", pktDelim},
+		{"", pktResponseEnd},
+		{"no newline", pktData},
+	}
+	for _, w := range want {
+		payload, typ, err := p.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(payload) != w.payload || typ != w.typ {
+			t.Errorf("next() = %q, %v, want %q, %v", payload, typ, w.payload, w.typ)
+		}
+	}
+
+	_, _, err := p.next()
+	var protocolErr *GitProtocolError
+	if !errors.As(err, &protocolErr) || protocolErr.Message != "access denied" {
+		t.Errorf("next() error = %v, want the ERR packet's message", err)
+	}
+}
+
+func TestPktReaderInvalid(t *testing.T) {
+	for _, input := range []string{
+		"0003",
+		"zzzz",
+		"fff1" + strings.Repeat("x", 65520),
+		"000ashort",
+		"00",
+	} {
+		if _, _, err := newPktReader("remote", strings.NewReader(input)).next(); err == nil {
+			t.Errorf("next() of %.10q succeeded, want error", input)
+		}
+	}
+}
+
+func TestReadAdvertisementV0(t *testing.T) {
+	input := pktLine("# service=git-upload-pack\n") + "0000" +
+		pktLine("1111111111111111111111111111111111111111 HEAD\x00multi_ack symref=HEAD:refs/heads/main agent=git/2.43\n") +
+		pktLine("1111111111111111111111111111111111111111 refs/heads/main\n") +
+		pktLine("2222222222222222222222222222222222222222 refs/tags/v1.0.0\n") +
+		pktLine("3333333333333333333333333333333333333333 refs/tags/v1.0.0^{}\n") +
+		"0000"
+
+	adv, err := readAdvertisement(newPktReader("remote", strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adv.v2 {
+		t.Error("advertisement is v2, want v0")
+	}
+	if want := []string{"HEAD", "refs/heads/main", "refs/tags/v1.0.0", "refs/tags/v1.0.0^{}"}; !slices.Equal(adv.refs, want) {
+		t.Errorf("refs = %v, want %v", adv.refs, want)
+	}
+	if value, ok := adv.capability("symref"); !ok || value != "HEAD:refs/heads/main" {
+		t.Errorf("capability(symref) = %q, %v", value, ok)
+	}
+	if want := []string{"v1.0.0"}; !slices.Equal(tagsFromRefs(adv.refs), want) {
+		t.Errorf("tagsFromRefs() = %v, want %v", tagsFromRefs(adv.refs), want)
+	}
+}
+
+func TestReadAdvertisementEmptyRepository(t *testing.T) {
+	input := pktLine("0000000000000000000000000000000000000000 capabilities^{}\x00agent=git/2.43\n") + "0000"
+	adv, err := readAdvertisement(newPktReader("remote", strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(adv.refs) != 0 {
+		t.Errorf("refs = %v, want none", adv.refs)
+	}
+}
+
+func TestLsRefsV2(t *testing.T) {
+	input := pktLine("version 2\n") + pktLine("agent=git/2.43\n") + pktLine("ls-refs=unborn\n") +
+		pktLine("object-format=sha256\n") + "0000"
+	adv, err := readAdvertisement(newPktReader("remote", strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !adv.v2 {
+		t.Fatal("advertisement is v0, want v2")
+	}
+	if _, ok := adv.capability("ls-refs"); !ok {
+		t.Error("capability(ls-refs) not found")
+	}
+
+	request := lsRefsRequest(adv, []string{"refs/tags/v1."})
+	want := "0014command=ls-refs\n" +
+		pktLine("agent="+userAgent+"\n") +
+		"0019object-format=sha256\n" +
+		"0001" +
+		"001dref-prefix refs/tags/v1.\n" +
+		"0000"
+	if request != want {
+		t.Errorf("lsRefsRequest() = %q, want %q", request, want)
+	}
+
+	response := pktLine("2222222222222222222222222222222222222222 refs/tags/v1.0.0\n") +
+		pktLine("4444444444444444444444444444444444444444 refs/tags/v1.1.0 peeled:5555555555555555555555555555555555555555\n") +
+		"0000"
+	refs, err := readLsRefs(newPktReader("remote", strings.NewReader(response)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"refs/tags/v1.0.0", "refs/tags/v1.1.0"}; !slices.Equal(refs, want) {
+		t.Errorf("readLsRefs() = %v, want %v", refs, want)
+	}
+
+	if _, err := readLsRefs(newPktReader("remote", strings.NewReader(pktLine("garbage\n")+"0000"))); err == nil {
+		t.Error("readLsRefs() of an invalid line succeeded, want error")
+	}
+}
@@ -0,0 +1,341 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// sshRemote is a git remote reached over SSH, given as
+// ssh://[user@]host[:port]/path or in the scp-like form [user@]host:path.
+type sshRemote struct {
+	user string
+	host string
+	port string
+	path string
+}
+
+// addr returns the host and port to connect to.
+func (r *sshRemote) addr() string {
+	port := r.port
+	if port == "" {
+		port = "22"
+	}
+	return net.JoinHostPort(r.host, port)
+}
+
+func parseSSHRemote(repoURL string) (*sshRemote, bool) {
+	if scheme, _, found := strings.Cut(repoURL, "://"); found {
+		switch scheme {
+		case "ssh", "git+ssh", "ssh+git":
+		default:
+			return nil, false
+		}
+		u, err := url.Parse(repoURL)
+		if err != nil || u.Hostname() == "" {
+			return nil, false
+		}
+		remote := &sshRemote{user: u.User.Username(), host: u.Hostname(), port: u.Port(), path: u.Path}
+		// ssh://host/~user/repo is relative to the user's home directory
+		if strings.HasPrefix(remote.path, "/~") {
+			remote.path = remote.path[1:]
+		}
+		return remote, true
+	}
+
+	// scp-like syntax is only recognised if there is no slash before the
+	// first colon, as git does; a bracketed host may contain colons
+	colon := strings.Index(repoURL, ":")
+	if strings.HasPrefix(repoURL, "[") || strings.Contains(repoURL, "@[") {
+		if end := strings.Index(repoURL, "]:"); end >= 0 {
+			colon = end + 1
+		}
+	}
+	if colon <= 0 || strings.Contains(repoURL[:colon], "/") || isLocalGitURL(repoURL) {
+		return nil, false
+	}
+	remote := &sshRemote{host: repoURL[:colon], path: repoURL[colon+1:]}
+	if at := strings.LastIndex(remote.host, "@"); at >= 0 {
+		remote.user, remote.host = remote.host[:at], remote.host[at+1:]
+	}
+	remote.host = strings.Trim(remote.host, "[]")
+	return remote, remote.host != "" && remote.path != ""
+}
+
+// listSSH lists the refs of an SSH remote matching prefixes by running
+// git-upload-pack on the server, asking for protocol v2 through the
+// GIT_PROTOCOL environment variable.
+func (g *GitScanner) listSSH(ctx context.Context, repo *config.Repository, remote *sshRemote, prefixes []string) ([]string, error) {
+	clientConfig, closeAgent, err := g.sshClientConfig(ctx, repo, remote)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	addr := remote.addr()
+	display := clientConfig.User + "@" + addr + ":" + remote.path
+
+	if g.verbose {
+		logf(ctx, "Connecting: ssh://%s\n", addr)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("connecting to %s did not finish: %w", addr, ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	// Unblock the handshake and any read once the context is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("connecting to %s did not finish: %w", addr, ctx.Err())
+		}
+		return nil, classifySSHError(addr, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, g.wireError(ctx, display, err)
+	}
+	defer session.Close()
+
+	// Servers that do not accept the variable speak protocol v0
+	_ = session.Setenv("GIT_PROTOCOL", "version=2")
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	if err := session.Start("git-upload-pack " + shellQuote(remote.path)); err != nil {
+		return nil, g.wireError(ctx, display, err)
+	}
+
+	// The server's stderr explains why the advertisement is missing, e.g.
+	// "ERROR: Repository not found."
+	withStderr := func(err error) error {
+		var protocolErr *GitProtocolError
+		if !errors.As(err, &protocolErr) {
+			stdin.Close()
+			session.Wait()
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				err = &GitProtocolError{URL: display, Message: message}
+			}
+		}
+		return g.wireError(ctx, display, err)
+	}
+
+	reader := newPktReader(display, stdout)
+	adv, err := readAdvertisement(reader)
+	if err != nil {
+		return nil, withStderr(err)
+	}
+
+	refs := adv.refs
+	if adv.v2 {
+		if _, ok := adv.capability("ls-refs"); !ok {
+			return nil, Permanent(&GitProtocolError{URL: display, Message: "server does not support ls-refs"})
+		}
+		if g.verbose {
+			logf(ctx, "Listing refs with protocol v2 (ref-prefix %s)\n", strings.Join(prefixes, ", "))
+		}
+		if _, err := stdin.Write([]byte(lsRefsRequest(adv, prefixes))); err != nil {
+			return nil, withStderr(err)
+		}
+		if refs, err = readLsRefs(reader); err != nil {
+			return nil, withStderr(err)
+		}
+	} else {
+		if g.verbose {
+			logf(ctx, "Server does not support protocol v2, using the ref advertisement\n")
+		}
+		// A flush-pkt instead of a want list ends the conversation
+		if _, err := stdin.Write([]byte("0000")); err != nil {
+			return nil, withStderr(err)
+		}
+	}
+	stdin.Close()
+
+	return refs, nil
+}
+
+// sshClientConfig authenticates like the exec-based transport: with the key
+// file named by Auth.EnvVariable for auth type "ssh", which like
+// StrictHostKeyChecking=no accepts any host key, or otherwise with the SSH
+// agent and default key files, verifying the host key against known_hosts.
+// The returned function closes the connection to the agent.
+func (g *GitScanner) sshClientConfig(ctx context.Context, repo *config.Repository, remote *sshRemote) (*ssh.ClientConfig, func(), error) {
+	clientConfig := &ssh.ClientConfig{
+		User:          remote.user,
+		ClientVersion: "SSH-2.0-" + userAgent,
+	}
+	if clientConfig.User == "" {
+		clientConfig.User = "git"
+	}
+
+	if repo.Auth != nil && repo.Auth.EnvVariable != "" {
+		keyPath, err := authToken(repo)
+		if err != nil {
+			return nil, nil, err
+		}
+		if repo.Auth.Type != "ssh" {
+			return nil, nil, Permanent(fmt.Errorf("unsupported authentication type for SSH remotes: %s", repo.Auth.Type))
+		}
+		signer, err := loadSSHKey(keyPath)
+		if err != nil {
+			return nil, nil, Permanent(err)
+		}
+		clientConfig.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+		clientConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return clientConfig, func() {}, nil
+	}
+
+	home, _ := os.UserHomeDir()
+
+	var knownHostsFiles []string
+	for _, file := range []string{filepath.Join(home, ".ssh", "known_hosts"), "/etc/ssh/ssh_known_hosts"} {
+		if _, err := os.Stat(file); err == nil {
+			knownHostsFiles = append(knownHostsFiles, file)
+		}
+	}
+	if len(knownHostsFiles) == 0 {
+		return nil, nil, Permanent(fmt.Errorf("host key verification failed: no known_hosts file to verify %s against", remote.host))
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFiles...)
+	if err != nil {
+		return nil, nil, Permanent(fmt.Errorf("failed to read known_hosts: %w", err))
+	}
+	clientConfig.HostKeyCallback = hostKeyCallback
+	// Have the server present a key known_hosts has rather than the one it
+	// prefers, as OpenSSH does
+	clientConfig.HostKeyAlgorithms = knownHostKeyAlgorithms(hostKeyCallback, remote.addr())
+
+	var agentClient agent.ExtendedAgent
+	closeAgent := func() {}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			agentClient = agent.NewClient(conn)
+			closeAgent = func() { conn.Close() }
+		} else if g.verbose {
+			logf(ctx, "Cannot connect to SSH agent: %v\n", err)
+		}
+	}
+
+	clientConfig.Auth = []ssh.AuthMethod{ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		var signers []ssh.Signer
+		if agentClient != nil {
+			if agentSigners, err := agentClient.Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			signer, err := loadSSHKey(filepath.Join(home, ".ssh", name))
+			if err == nil {
+				signers = append(signers, signer)
+			} else if g.verbose && !errors.Is(err, os.ErrNotExist) {
+				logf(ctx, "Skipping SSH key: %v\n", err)
+			}
+		}
+		return signers, nil
+	})}
+	return clientConfig, closeAgent, nil
+}
+
+// knownHostKeyAlgorithms returns the host key algorithms for the keys the
+// known_hosts callback has for addr, or nil to use the defaults if it has
+// none. The callback reports them when it rejects a key it does not know.
+func knownHostKeyAlgorithms(callback ssh.HostKeyCallback, addr string) []string {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil
+	}
+	probe, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil
+	}
+
+	var keyErr *knownhosts.KeyError
+	if err := callback(addr, &net.TCPAddr{}, probe); !errors.As(err, &keyErr) {
+		return nil
+	}
+	var algorithms []string
+	for _, known := range keyErr.Want {
+		keyAlgorithms := []string{known.Key.Type()}
+		if known.Key.Type() == ssh.KeyAlgoRSA {
+			keyAlgorithms = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+		for _, algorithm := range keyAlgorithms {
+			if !slices.Contains(algorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
+	return algorithms
+}
+
+func loadSSHKey(path string) (ssh.Signer, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	var passphraseErr *ssh.PassphraseMissingError
+	if errors.As(err, &passphraseErr) {
+		return nil, fmt.Errorf("SSH key %s is protected by a passphrase; add it to an SSH agent instead", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// classifySSHError marks failed SSH handshakes as permanent where the
+// credentials or the host key were rejected.
+func classifySSHError(addr string, err error) error {
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) == 0 {
+			return Permanent(fmt.Errorf("host key verification failed: %s is not in known_hosts", addr))
+		}
+		return Permanent(fmt.Errorf("host key verification failed: host key of %s has changed", addr))
+	}
+	if strings.Contains(err.Error(), "unable to authenticate") {
+		return Permanent(fmt.Errorf("authentication failed for %s: %w", addr, err))
+	}
+	return fmt.Errorf("failed to connect to %s: %w", addr, err)
+}
+
+// shellQuote quotes s for the remote shell the way git does.
+func shellQuote(s string) string {
+	return "'" + strings.NewReplacer("'", `'\''`, "!", `'\!'`).Replace(s) + "'"
+}
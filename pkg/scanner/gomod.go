@@ -0,0 +1,366 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+	"github.com/wellcom-rocks/updates-sucks/pkg/version"
+)
+
+const defaultGoProxy = "https://proxy.golang.org,direct"
+
+// GoModScanner lists the released versions of a Go module from a module
+// proxy, falling back to the module's git repository for private modules
+// or when GOPROXY says "direct".
+type GoModScanner struct {
+	verbose bool
+	git     *GitScanner
+}
+
+type goModInfo struct {
+	Version string `json:"Version"`
+}
+
+// goProxyEntry is one element of a GOPROXY list.
+type goProxyEntry struct {
+	URL string
+	// FallbackOnError is set for entries followed by '|', which fall back
+	// to the next entry on any error rather than only if the module is not
+	// found.
+	FallbackOnError bool
+}
+
+var (
+	// pseudoVersionRegex matches versions such as
+	// v0.0.0-20191109021931-daa7c04131f5, taken from golang.org/x/mod.
+	pseudoVersionRegex = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+	majorSuffixRegex   = regexp.MustCompile(`/v([2-9]|[1-9][0-9]+)$`)
+	gopkgInSuffixRegex = regexp.MustCompile(`\.v([0-9]+)(-unstable)?$`)
+	versionMajorRegex  = regexp.MustCompile(`^v([0-9]+)\.`)
+)
+
+func init() {
+	RegisterSource("gomod", func(verbose bool) Source {
+		return NewGoModScanner(verbose)
+	})
+}
+
+func NewGoModScanner(verbose bool) *GoModScanner {
+	return &GoModScanner{
+		verbose: verbose,
+		git:     NewGitScanner(verbose),
+	}
+}
+
+func (g *GoModScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.GoMod
+	if options == nil {
+		options = &config.GoMod{}
+	}
+
+	modulePath := strings.TrimSpace(repo.URL)
+	modulePath = strings.TrimPrefix(modulePath, "https://")
+	modulePath = strings.Trim(modulePath, "/")
+	if modulePath == "" {
+		return nil, Permanent(fmt.Errorf("no Go module configured: set url to the module path"))
+	}
+
+	private := options.Private
+	if private == "" {
+		private = os.Getenv("GONOPROXY")
+	}
+	if private == "" {
+		private = os.Getenv("GOPRIVATE")
+	}
+	if matchModulePatterns(private, modulePath) {
+		if g.verbose {
+			logf(ctx, "Module %s is private, fetching directly\n", modulePath)
+		}
+		return g.listDirect(ctx, repo, modulePath, options)
+	}
+
+	proxyList := options.Proxy
+	if proxyList == "" {
+		proxyList = os.Getenv("GOPROXY")
+	}
+	if proxyList == "" {
+		proxyList = defaultGoProxy
+	}
+
+	var lastErr error
+	for _, entry := range parseGoProxyList(proxyList) {
+		switch entry.URL {
+		case "off":
+			return nil, Permanent(fmt.Errorf("module lookup disabled by GOPROXY=off"))
+		case "direct":
+			return g.listDirect(ctx, repo, modulePath, options)
+		}
+
+		versions, err := g.listProxy(ctx, repo, entry.URL, modulePath)
+		if err == nil {
+			return filterModuleVersions(ctx, modulePath, versions, g.verbose), nil
+		}
+		if !entry.FallbackOnError && !isModuleNotFound(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = Permanent(fmt.Errorf("no module proxy configured"))
+	}
+	return nil, lastErr
+}
+
+// listProxy lists the versions known to a GOPROXY protocol server, using
+// @latest for modules that have no tagged versions in @v/list.
+func (g *GoModScanner) listProxy(ctx context.Context, repo *config.Repository, proxyURL, modulePath string) ([]string, error) {
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleURL := strings.TrimSuffix(proxyURL, "/") + "/" + escapeModulePath(modulePath)
+
+	body, err := httpGetBody(ctx, moduleURL+"/@v/list", header, g.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list module versions: %w", err)
+	}
+
+	versions := strings.Fields(string(body))
+	if len(versions) > 0 {
+		return versions, nil
+	}
+
+	var info goModInfo
+	if _, err := httpGetJSON(ctx, moduleURL+"/@latest", header, g.verbose, &info); err != nil {
+		return nil, fmt.Errorf("failed to query latest module version: %w", err)
+	}
+	return []string{info.Version}, nil
+}
+
+// listDirect lists the module's versions from the tags of its git
+// repository, the way the go command does for GOPROXY=direct. Tags of
+// modules in a subdirectory carry the subdirectory as prefix.
+func (g *GoModScanner) listDirect(ctx context.Context, repo *config.Repository, modulePath string, options *config.GoMod) ([]string, error) {
+	root := majorSuffixRegex.ReplaceAllString(modulePath, "")
+	subdir := ""
+	if parts := strings.Split(root, "/"); len(parts) > 3 && (parts[0] == "github.com" || parts[0] == "bitbucket.org") {
+		root = strings.Join(parts[:3], "/")
+		subdir = strings.Join(parts[3:], "/")
+	}
+
+	gitRepo := *repo
+	gitRepo.URL = options.GitURL
+	if gitRepo.URL == "" {
+		gitRepo.URL = "https://" + root
+	}
+
+	tags, err := g.git.ListVersions(ctx, &gitRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, tag := range tags {
+		if subdir != "" {
+			var ok bool
+			if tag, ok = strings.CutPrefix(tag, subdir+"/"); !ok {
+				continue
+			}
+		}
+		versions = append(versions, tag)
+	}
+
+	// Only modules at the repository root have +incompatible versions
+	if subdir == "" && !majorSuffixRegex.MatchString(modulePath) && !strings.HasPrefix(modulePath, "gopkg.in/") {
+		if versions, err = g.markIncompatible(ctx, &gitRepo, versions); err != nil {
+			return nil, err
+		}
+	}
+
+	return filterModuleVersions(ctx, modulePath, versions, g.verbose), nil
+}
+
+// markIncompatible adds +incompatible to the v2+ tags of a module without a
+// major version suffix, as go list -m -versions does: only if the latest
+// v0/v1 tag has no go.mod file, and per major version only if its latest
+// tag has none either. Without the git binary to inspect the tags of a
+// remote repository the v2+ tags are left out.
+func (g *GoModScanner) markIncompatible(ctx context.Context, gitRepo *config.Repository, tags []string) ([]string, error) {
+	var result, compatible []string
+	incompatible := make(map[int][]string)
+	for _, tag := range tags {
+		v, err := version.ParseSemVer(tag)
+		if err != nil || !strings.HasPrefix(tag, "v") || v.Build != "" || pseudoVersionRegex.MatchString(tag) {
+			result = append(result, tag)
+			continue
+		}
+		if v.Major < 2 {
+			result = append(result, tag)
+			compatible = append(compatible, tag)
+			continue
+		}
+		incompatible[v.Major] = append(incompatible[v.Major], tag)
+	}
+	if len(incompatible) == 0 {
+		return tags, nil
+	}
+
+	hasGoMod := func(tag string) (bool, error) {
+		return g.git.hasFile(ctx, gitRepo, tag, "go.mod")
+	}
+	if sorted := version.SortSemVer(compatible); len(sorted) > 0 {
+		ok, err := hasGoMod(sorted[len(sorted)-1])
+		if err != nil {
+			return g.skipIncompatible(ctx, result, err)
+		}
+		if ok {
+			return result, nil
+		}
+	}
+
+	majors := make([]int, 0, len(incompatible))
+	for major := range incompatible {
+		majors = append(majors, major)
+	}
+	slices.Sort(majors)
+	for _, major := range majors {
+		sorted := version.SortSemVer(incompatible[major])
+		ok, err := hasGoMod(sorted[len(sorted)-1])
+		if err != nil {
+			return g.skipIncompatible(ctx, result, err)
+		}
+		if ok {
+			continue
+		}
+		for _, tag := range sorted {
+			result = append(result, tag+"+incompatible")
+		}
+	}
+	return result, nil
+}
+
+// skipIncompatible returns the tags without +incompatible versions if err
+// is the git binary missing, and err otherwise.
+func (g *GoModScanner) skipIncompatible(ctx context.Context, tags []string, err error) ([]string, error) {
+	if !errors.Is(err, exec.ErrNotFound) {
+		return nil, err
+	}
+	if g.verbose {
+		logf(ctx, "Ignoring v2+ tags, as git is needed to check them for a go.mod file\n")
+	}
+	return tags, nil
+}
+
+// filterModuleVersions drops pseudo-versions and versions whose major
+// version does not belong to modulePath: modules with a /vN suffix only
+// have vN versions, modules without one have v0 and v1 versions plus
+// +incompatible versions of repositories without a go.mod file.
+func filterModuleVersions(ctx context.Context, modulePath string, versions []string, verbose bool) []string {
+	pathMajor := -1
+	if matches := majorSuffixRegex.FindStringSubmatch(modulePath); matches != nil {
+		pathMajor, _ = strconv.Atoi(matches[1])
+	} else if matches := gopkgInSuffixRegex.FindStringSubmatch(modulePath); matches != nil && strings.HasPrefix(modulePath, "gopkg.in/") {
+		pathMajor, _ = strconv.Atoi(matches[1])
+	}
+
+	var result []string
+	for _, v := range versions {
+		if pseudoVersionRegex.MatchString(v) {
+			if verbose {
+				logf(ctx, "Ignoring pseudo-version '%s'\n", v)
+			}
+			continue
+		}
+
+		matches := versionMajorRegex.FindStringSubmatch(v)
+		if matches == nil {
+			continue
+		}
+		major, _ := strconv.Atoi(matches[1])
+
+		switch {
+		case pathMajor >= 0 && major != pathMajor && !(pathMajor == 1 && major == 0):
+			continue
+		case pathMajor < 0 && major >= 2 && !strings.HasSuffix(v, "+incompatible"):
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func parseGoProxyList(list string) []goProxyEntry {
+	var entries []goProxyEntry
+	for list != "" {
+		i := strings.IndexAny(list, ",|")
+		entry := goProxyEntry{URL: list}
+		if i >= 0 {
+			entry.URL = list[:i]
+			entry.FallbackOnError = list[i] == '|'
+			list = list[i+1:]
+		} else {
+			list = ""
+		}
+		if entry.URL = strings.TrimSpace(entry.URL); entry.URL != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// matchModulePatterns reports whether any of the comma-separated glob
+// patterns matches modulePath or one of its path prefixes, as GOPRIVATE
+// patterns do.
+func matchModulePatterns(patterns, modulePath string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		n := strings.Count(pattern, "/") + 1
+		prefix := modulePath
+		if parts := strings.SplitN(modulePath, "/", n+1); len(parts) > n {
+			prefix = strings.Join(parts[:n], "/")
+		}
+		if matched, _ := path.Match(pattern, prefix); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeModulePath applies the module proxy case encoding, which replaces
+// upper-case letters by '!' and their lower-case form.
+func escapeModulePath(modulePath string) string {
+	var b strings.Builder
+	for _, r := range modulePath {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isModuleNotFound reports whether a proxy answered 404 or 410, after which
+// the go command moves on to the next GOPROXY entry.
+func isModuleNotFound(err error) bool {
+	var statusErr *HTTPStatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone)
+}
@@ -0,0 +1,168 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestFilterModuleVersions(t *testing.T) {
+	versions := []string{
+		"v0.9.0",
+		"v1.0.0",
+		"v1.1.0-rc.1",
+		"v0.0.0-20191109021931-daa7c04131f5",
+		"v1.2.1-0.20200101000000-abcdefabcdef",
+		"v2.0.0",
+		"v2.1.0",
+		"v3.0.0+incompatible",
+		"latest",
+	}
+
+	tests := []struct {
+		modulePath string
+		want       []string
+	}{
+		{"example.com/mod", []string{"v0.9.0", "v1.0.0", "v1.1.0-rc.1", "v3.0.0+incompatible"}},
+		{"example.com/mod/v2", []string{"v2.0.0", "v2.1.0"}},
+		{"gopkg.in/yaml.v1", []string{"v0.9.0", "v1.0.0", "v1.1.0-rc.1"}},
+	}
+
+	for _, tt := range tests {
+		got := filterModuleVersions(context.Background(), tt.modulePath, versions, false)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("filterModuleVersions(%q) = %v, want %v", tt.modulePath, got, tt.want)
+		}
+	}
+}
+
+func TestParseGoProxyList(t *testing.T) {
+	got := parseGoProxyList("https://proxy.example.com|https://proxy.golang.org, direct,")
+	want := []goProxyEntry{
+		{URL: "https://proxy.example.com", FallbackOnError: true},
+		{URL: "https://proxy.golang.org"},
+		{URL: "direct"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseGoProxyList() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMatchModulePatterns(t *testing.T) {
+	tests := []struct {
+		patterns   string
+		modulePath string
+		want       bool
+	}{
+		{"example.com", "example.com/team/mod", true},
+		{"*.corp.example.com", "git.corp.example.com/mod", true},
+		{"example.com/team", "example.com/team/mod/v2", true},
+		{"example.com/other", "example.com/team/mod", false},
+		{"github.com/org/*,example.com", "github.com/org/repo/sub", true},
+		{"", "example.com/mod", false},
+	}
+	for _, tt := range tests {
+		if got := matchModulePatterns(tt.patterns, tt.modulePath); got != tt.want {
+			t.Errorf("matchModulePatterns(%q, %q) = %v, want %v", tt.patterns, tt.modulePath, got, tt.want)
+		}
+	}
+}
+
+func TestGoModProxy(t *testing.T) {
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken/github.com/!azure/mod/@v/list":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/good/github.com/!azure/mod/@v/list":
+			fmt.Fprint(w, "v1.0.0\nv1.1.0\nv0.0.0-20191109021931-daa7c04131f5\n")
+		case "/good/example.com/untagged/@v/list":
+		case "/good/example.com/untagged/@latest":
+			fmt.Fprint(w, `{"Version": "v0.3.0"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		module  string
+		proxy   string
+		want    []string
+		wantErr bool
+	}{
+		{"not found falls back", "github.com/Azure/mod", server.URL + "/empty," + server.URL + "/good", []string{"v1.0.0", "v1.1.0"}, false},
+		{"error falls back after pipe", "github.com/Azure/mod", server.URL + "/broken|" + server.URL + "/good", []string{"v1.0.0", "v1.1.0"}, false},
+		{"error stops after comma", "github.com/Azure/mod", server.URL + "/broken," + server.URL + "/good", nil, true},
+		{"latest of module without list", "example.com/untagged", server.URL + "/good", []string{"v0.3.0"}, false},
+		{"off", "github.com/Azure/mod", "off", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{
+				Type:  "gomod",
+				URL:   tt.module,
+				GoMod: &config.GoMod{Proxy: tt.proxy},
+			}
+			versions, err := NewGoModScanner(false).ListVersions(context.Background(), repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListVersions() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoModDirect(t *testing.T) {
+	dir := newFixtureRepo(t)
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	repo := &config.Repository{
+		Type:  "gomod",
+		URL:   "example.com/mod",
+		GoMod: &config.GoMod{Proxy: "direct", GitURL: dir},
+	}
+	versions, err := NewGoModScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(versions)
+	// Without a go.mod file, v9.0.0 is an +incompatible version of a module
+	// path without /v9
+	if want := []string{"v1.0.0", "v1.1.0", "v9.0.0+incompatible"}; !slices.Equal(versions, want) {
+		t.Errorf("ListVersions() = %v, want %v", versions, want)
+	}
+
+	// Once the latest v1 version has a go.mod file, the go command no
+	// longer lists +incompatible versions
+	git := fixtureGit(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/mod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "go.mod")
+	git("commit", "--quiet", "-m", "add go.mod")
+	git("tag", "v1.2.0")
+
+	versions, err = NewGoModScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(versions)
+	if want := []string{"v1.0.0", "v1.1.0", "v1.2.0"}; !slices.Equal(versions, want) {
+		t.Errorf("ListVersions() with go.mod = %v, want %v", versions, want)
+	}
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// HelmScanner lists the versions of a chart published in a Helm chart
+// repository index, or in an OCI registry for oci:// URLs.
+type HelmScanner struct {
+	verbose bool
+	oci     *OCIScanner
+}
+
+type helmIndex struct {
+	Entries map[string][]helmChartVersion `yaml:"entries"`
+}
+
+type helmChartVersion struct {
+	Version    string `yaml:"version"`
+	AppVersion string `yaml:"appVersion"`
+}
+
+func init() {
+	RegisterSource("helm", func(verbose bool) Source {
+		return NewHelmScanner(verbose)
+	})
+}
+
+func NewHelmScanner(verbose bool) *HelmScanner {
+	return &HelmScanner{
+		verbose: verbose,
+		oci:     NewOCIScanner(verbose),
+	}
+}
+
+func (h *HelmScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.Helm
+	if options == nil {
+		options = &config.Helm{}
+	}
+
+	if strings.HasPrefix(repo.URL, "oci://") {
+		return h.listOCIVersions(ctx, repo, options)
+	}
+
+	if options.Chart == "" {
+		return nil, Permanent(fmt.Errorf("no chart configured: set helm.chart"))
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	indexURL := strings.TrimSuffix(repo.URL, "/") + "/index.yaml"
+	body, err := httpGetBody(ctx, indexURL, header, h.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chart index: %w", err)
+	}
+
+	var index helmIndex
+	if err := yaml.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse chart index %s: %w", indexURL, err)
+	}
+
+	entries, ok := index.Entries[options.Chart]
+	if !ok {
+		return nil, Permanent(fmt.Errorf("chart '%s' not found in %s", options.Chart, indexURL))
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if options.UseAppVersion {
+			if entry.AppVersion != "" {
+				versions = append(versions, entry.AppVersion)
+			}
+		} else {
+			versions = append(versions, entry.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// listOCIVersions lists the tags of a chart pushed to an OCI registry with
+// helm push, where the URL names either the chart itself or its parent
+// namespace if helm.chart is set.
+func (h *HelmScanner) listOCIVersions(ctx context.Context, repo *config.Repository, options *config.Helm) ([]string, error) {
+	if options.UseAppVersion {
+		return nil, Permanent(fmt.Errorf("helm.useAppVersion is not supported for OCI-hosted charts"))
+	}
+
+	ref, err := parseOCIReference(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	if options.Chart != "" && path.Base(ref.Repository) != options.Chart {
+		ref.Repository += "/" + options.Chart
+	}
+
+	tags, err := h.oci.listTags(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// OCI tags cannot contain '+', so helm stores SemVer build metadata
+	// with '_' instead
+	versions := make([]string, len(tags))
+	for i, tag := range tags {
+		versions[i] = strings.ReplaceAll(tag, "_", "+")
+	}
+	return versions, nil
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const helmIndexYAML = `apiVersion: v1
+entries:
+  app:
+    - version: 1.2.0
+      appVersion: "2.5.1"
+    - version: 1.1.0
+      appVersion: "2.4.0"
+    - version: 1.0.0
+  other:
+    - version: 9.9.9
+`
+
+func TestHelmIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/charts/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, helmIndexYAML)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		options config.Helm
+		want    []string
+	}{
+		{"chart versions", config.Helm{Chart: "app"}, []string{"1.2.0", "1.1.0", "1.0.0"}},
+		{"app versions", config.Helm{Chart: "app", UseAppVersion: true}, []string{"2.5.1", "2.4.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{Type: "helm", URL: server.URL + "/charts/", Helm: &tt.options}
+			versions, err := NewHelmScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+
+	repo := &config.Repository{Type: "helm", URL: server.URL + "/charts", Helm: &config.Helm{Chart: "missing"}}
+	if _, err := NewHelmScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() of a missing chart error = %v, want a permanent error", err)
+	}
+}
+
+func TestHelmOCI(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/charts/app/tags/list" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name": "charts/app", "tags": ["1.0.0", "1.1.0_build.5"]}`)
+	}))
+	defer server.Close()
+
+	client := httpClient
+	httpClient = server.Client()
+	defer func() { httpClient = client }()
+
+	repo := &config.Repository{
+		Type: "helm",
+		URL:  "oci://" + strings.TrimPrefix(server.URL, "https://") + "/charts",
+		Helm: &config.Helm{Chart: "app"},
+	}
+	versions, err := NewHelmScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1.0.0", "1.1.0+build.5"}; !slices.Equal(versions, want) {
+		t.Errorf("ListVersions() = %v, want %v", versions, want)
+	}
+}
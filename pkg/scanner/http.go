@@ -0,0 +1,255 @@
+package scanner
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const userAgent = "updates-sucks"
+
+var httpClient = &http.Client{}
+
+// HTTPStatusError is returned when a registry or API answers with a non-2xx
+// status code.
+type HTTPStatusError struct {
+	// Method is the request method; empty means GET.
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+func (e *HTTPStatusError) Error() string {
+	method := e.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return fmt.Sprintf("%s %s: %s", method, e.URL, e.Status)
+}
+
+// httpGet performs a GET request and returns the response if the server
+// answered with a 2xx status. The caller must close the response body.
+// Client errors are permanent, except for those signalling rate limiting.
+func httpGet(ctx context.Context, rawURL string, header http.Header, verbose bool) (*http.Response, error) {
+	return httpDo(ctx, http.MethodGet, rawURL, header, nil, verbose)
+}
+
+// httpPost is httpGet for POST requests with the given body.
+func httpPost(ctx context.Context, rawURL string, header http.Header, body io.Reader, verbose bool) (*http.Response, error) {
+	return httpDo(ctx, http.MethodPost, rawURL, header, body, verbose)
+}
+
+func httpDo(ctx context.Context, method, rawURL string, header http.Header, body io.Reader, verbose bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("invalid request URL: %w", err))
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	if verbose {
+		if method == http.MethodGet {
+			logf(ctx, "Fetching: %s\n", rawURL)
+		} else {
+			logf(ctx, "Sending: %s %s\n", method, rawURL)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s %s did not finish: %w", method, rawURL, ctx.Err())
+		}
+		// *url.Error already names the method and URL
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	statusErr := &HTTPStatusError{Method: method, URL: rawURL, StatusCode: resp.StatusCode, Status: resp.Status, Header: resp.Header}
+	if isTransientStatus(resp) {
+		return nil, statusErr
+	}
+	return nil, Permanent(statusErr)
+}
+
+func isTransientStatus(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 500:
+		return true
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusForbidden:
+		// Rate limits are reported as 403 by some APIs (GitHub among them)
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
+
+// httpGetJSON fetches rawURL and decodes the JSON response body into v. It
+// returns the response headers so callers can follow pagination links.
+func httpGetJSON(ctx context.Context, rawURL string, header http.Header, verbose bool, v any) (http.Header, error) {
+	resp, err := httpGet(ctx, rawURL, header, verbose)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("GET %s did not finish: %w", rawURL, ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to decode response from %s: %w", rawURL, err)
+	}
+	return resp.Header, nil
+}
+
+// httpGetBody fetches rawURL and returns the complete response body.
+func httpGetBody(ctx context.Context, rawURL string, header http.Header, verbose bool) ([]byte, error) {
+	resp, err := httpGet(ctx, rawURL, header, verbose)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("GET %s did not finish: %w", rawURL, ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to read response from %s: %w", rawURL, err)
+	}
+	return body, nil
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]+)>\s*;[^,]*rel="?next"?`)
+
+// nextLink returns the target of the rel="next" entry of an RFC 8288 Link
+// header, or "" if there is none.
+func nextLink(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		if matches := linkNextRegex.FindStringSubmatch(link); matches != nil {
+			return matches[1]
+		}
+	}
+	return ""
+}
+
+// nextPage returns the next page named by the Link header of the response
+// to current, resolved against current, or "" if there is none. Links to
+// another scheme or host are refused, as following them would send the
+// request's credentials there.
+func nextPage(current string, header http.Header) (string, error) {
+	link := nextLink(header)
+	if link == "" {
+		return "", nil
+	}
+	currentURL, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return "", Permanent(fmt.Errorf("invalid pagination link %q: %w", link, err))
+	}
+	nextURL := currentURL.ResolveReference(linkURL)
+	if nextURL.Scheme != currentURL.Scheme || nextURL.Host != currentURL.Host {
+		return "", Permanent(fmt.Errorf("refusing to follow pagination link %q to another host", link))
+	}
+	return nextURL.String(), nil
+}
+
+// authToken returns the secret configured in repo.Auth, or "" if the
+// repository does not use authentication.
+func authToken(repo *config.Repository) (string, error) {
+	if repo.Auth == nil || repo.Auth.EnvVariable == "" {
+		return "", nil
+	}
+	token := os.Getenv(repo.Auth.EnvVariable)
+	if token == "" {
+		return "", Permanent(fmt.Errorf("authentication token not found in environment variable %s", repo.Auth.EnvVariable))
+	}
+	return token, nil
+}
+
+// httpAuthHeader returns the request headers for the credentials in
+// repo.Auth: a bearer token for type "token", or basic authentication with
+// Auth.Username for type "basic".
+func httpAuthHeader(repo *config.Repository) (http.Header, error) {
+	header := http.Header{}
+	token, err := authToken(repo)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return header, nil
+	}
+
+	switch repo.Auth.Type {
+	case "", "token":
+		header.Set("Authorization", "Bearer "+token)
+	case "basic":
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(repo.Auth.Username+":"+token)))
+	default:
+		return nil, Permanent(fmt.Errorf("unsupported authentication type for %s repositories: %s", repo.Type, repo.Auth.Type))
+	}
+	return header, nil
+}
+
+// splitRepoURL splits a repository URL into the web base URL of its host
+// and the project path, e.g. "https://github.com/owner/repo.git" into
+// "https://github.com" and "owner/repo". SCP-style SSH URLs are accepted, and
+// a bare "owner/repo" path is resolved against defaultBaseURL.
+func splitRepoURL(rawURL, defaultBaseURL string) (string, string, error) {
+	if !strings.Contains(rawURL, "://") {
+		// git@host:owner/repo.git
+		if at := strings.Index(rawURL, "@"); at >= 0 {
+			if colon := strings.Index(rawURL[at:], ":"); colon >= 0 {
+				host := rawURL[at+1 : at+colon]
+				return "https://" + host, trimURLPath(rawURL[at+colon+1:]), nil
+			}
+		}
+		if defaultBaseURL == "" {
+			return "", "", Permanent(fmt.Errorf("invalid repository URL: %s", rawURL))
+		}
+		return defaultBaseURL, trimURLPath(rawURL), nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", Permanent(fmt.Errorf("invalid repository URL: %w", err))
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return u.Scheme + "://" + u.Host, trimURLPath(u.Path), nil
+	}
+	// ssh:// and git:// remotes are served over HTTPS by the API, and their
+	// port belongs to the git transport
+	return "https://" + u.Hostname(), trimURLPath(u.Path), nil
+}
+
+// trimURLPath returns the path of a repository URL without leading or
+// trailing slashes and without a ".git" suffix, accepting bare paths such as
+// "owner/repo" as well.
+func trimURLPath(path string) string {
+	path = strings.Trim(path, "/")
+	return strings.TrimSuffix(path, ".git")
+}
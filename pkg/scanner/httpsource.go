@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// tokenPlaceholder is replaced by the token from Auth.EnvVariable in the
+// configured headers of "http" repositories.
+const tokenPlaceholder = "${token}"
+
+// HTTPScanner extracts versions from an arbitrary HTTP endpoint, such as a
+// vendor's JSON API or download page, using JSONPath or a regex.
+type HTTPScanner struct {
+	verbose bool
+}
+
+func init() {
+	RegisterSource("http", func(verbose bool) Source {
+		return NewHTTPScanner(verbose)
+	})
+}
+
+func NewHTTPScanner(verbose bool) *HTTPScanner {
+	return &HTTPScanner{verbose: verbose}
+}
+
+func (h *HTTPScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.HTTP
+	if options == nil || (options.JSONPath == "") == (options.Regex == "") {
+		return nil, Permanent(fmt.Errorf("set exactly one of http.jsonPath and http.regex"))
+	}
+
+	header, err := h.header(repo, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	if options.JSONPath != "" {
+		path, err := parseJSONPath(options.JSONPath)
+		if err != nil {
+			return nil, Permanent(fmt.Errorf("invalid http.jsonPath: %w", err))
+		}
+
+		body, err := httpGetBody(ctx, repo.URL, header, h.verbose)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", repo.URL, err)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var document any
+		if err := decoder.Decode(&document); err != nil {
+			return nil, fmt.Errorf("failed to parse response of %s: %w", repo.URL, err)
+		}
+
+		for _, value := range path.evaluate(document) {
+			switch value := value.(type) {
+			case string:
+				versions = append(versions, value)
+			case json.Number:
+				versions = append(versions, value.String())
+			default:
+				if h.verbose {
+					logf(ctx, "Ignoring non-scalar JSONPath match %v\n", value)
+				}
+			}
+		}
+	} else {
+		pattern, err := regexp.Compile(options.Regex)
+		if err != nil {
+			return nil, Permanent(fmt.Errorf("invalid http.regex: %w", err))
+		}
+		group := pattern.SubexpIndex("version")
+		if group < 0 {
+			group = 1
+		}
+		if pattern.NumSubexp() < group {
+			return nil, Permanent(fmt.Errorf("http.regex has no capture group"))
+		}
+
+		body, err := httpGetBody(ctx, repo.URL, header, h.verbose)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", repo.URL, err)
+		}
+
+		for _, match := range pattern.FindAllSubmatch(body, -1) {
+			if len(match[group]) > 0 {
+				versions = append(versions, string(match[group]))
+			}
+		}
+	}
+
+	// Download pages tend to mention the same version several times
+	seen := map[string]bool{}
+	unique := versions[:0]
+	for _, v := range versions {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	if len(unique) == 0 {
+		return nil, Permanent(fmt.Errorf("no versions found in %s", repo.URL))
+	}
+	return unique, nil
+}
+
+// header returns the configured headers with the token substituted. If no
+// header refers to the token, it is sent as in other HTTP sources.
+func (h *HTTPScanner) header(repo *config.Repository, options *config.HTTP) (http.Header, error) {
+	usesToken := false
+	for _, value := range options.Headers {
+		if strings.Contains(value, tokenPlaceholder) {
+			usesToken = true
+		}
+	}
+
+	header := http.Header{}
+	if usesToken {
+		token, err := authToken(repo)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, Permanent(fmt.Errorf("http.headers refer to %s but auth.envVariable is not set", tokenPlaceholder))
+		}
+		for name, value := range options.Headers {
+			header.Set(name, strings.ReplaceAll(value, tokenPlaceholder, token))
+		}
+		return header, nil
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range options.Headers {
+		header.Set(name, value)
+	}
+	return header, nil
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api":
+			if got := r.Header.Get("X-Api-Key"); got != "key-secret" {
+				t.Errorf("X-Api-Key = %q, want %q", got, "key-secret")
+			}
+			fmt.Fprint(w, `{"releases": [{"version": "1.0.0"}, {"version": "1.1.0"}, {"version": 2}, {"version": {"major": 3}}]}`)
+		case "/downloads":
+			if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+				t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+			}
+			fmt.Fprint(w, `<a href="tool-1.2.0.tar.gz">tool-1.2.0.tar.gz</a>
+<a href="tool-1.3.0.tar.gz">tool-1.3.0.tar.gz</a>
+<a href="tool-1.2.0.tar.gz">mirror</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEST_HTTP_TOKEN", "secret")
+
+	auth := &config.Auth{Type: "token", EnvVariable: "TEST_HTTP_TOKEN"}
+	tests := []struct {
+		name    string
+		path    string
+		options config.HTTP
+		want    []string
+	}{
+		{
+			"json path with token header",
+			"/api",
+			config.HTTP{JSONPath: "$.releases[*].version", Headers: map[string]string{"X-Api-Key": "key-${token}"}},
+			[]string{"1.0.0", "1.1.0", "2"},
+		},
+		{
+			"named group",
+			"/downloads",
+			config.HTTP{Regex: `tool-(?P<version>[0-9.]+)\.tar\.gz`},
+			[]string{"1.2.0", "1.3.0"},
+		},
+		{
+			"first group",
+			"/downloads",
+			config.HTTP{Regex: `href="tool-([0-9.]+)\.tar\.gz"`},
+			[]string{"1.2.0", "1.3.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{Type: "http", URL: server.URL + tt.path, Auth: auth, HTTP: &tt.options}
+			versions, err := NewHTTPScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+
+	for _, options := range []config.HTTP{
+		{},
+		{JSONPath: "$.a", Regex: "(.*)"},
+		{Regex: "tool-[0-9.]+"},
+		{Regex: `nothing-(\d+)`},
+	} {
+		repo := &config.Repository{Type: "http", URL: server.URL + "/downloads", Auth: auth, HTTP: &options}
+		if _, err := NewHTTPScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+			t.Errorf("ListVersions() with %+v error = %v, want a permanent error", options, err)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath expression. The supported subset covers
+// what is needed to pick versions out of API responses:
+//
+//	$.name, $['name']  child member
+//	$.list[0], [-1]    array element, negative indexes count from the end
+//	[*], .*            all members or elements
+//	..name             recursive descent
+//	[?(@.key == 'x')]  filter on ==, != or the existence of @.key
+type jsonPath []jsonPathStep
+
+type jsonPathStep struct {
+	recursive bool
+	wildcard  bool
+	name      string
+	index     *int
+	filter    *jsonPathFilter
+}
+
+type jsonPathFilter struct {
+	path jsonPath
+	// op is "==", "!=" or "" for an existence test.
+	op    string
+	value any
+}
+
+func parseJSONPath(expr string) (jsonPath, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") && !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("expression must start with '$': %s", expr)
+	}
+
+	var path jsonPath
+	rest := expr[1:]
+	for rest != "" {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			name, remainder := cutJSONPathName(rest)
+			if name == "" {
+				return nil, fmt.Errorf("missing member name after '..' in %s", expr)
+			}
+			step.wildcard = name == "*"
+			step.name = name
+			path = append(path, step)
+			rest = remainder
+			continue
+
+		case strings.HasPrefix(rest, "."):
+			name, remainder := cutJSONPathName(rest[1:])
+			if name == "" {
+				return nil, fmt.Errorf("missing member name after '.' in %s", expr)
+			}
+			step.wildcard = name == "*"
+			step.name = name
+			path = append(path, step)
+			rest = remainder
+			continue
+
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("unexpected %q in %s", rest, expr)
+		}
+
+		end := matchingBracket(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated '[' in %s", expr)
+		}
+		inner := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+
+		switch {
+		case inner == "*":
+			step.wildcard = true
+		case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`):
+			name, err := unquoteJSONPathString(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid member name %s in %s", inner, expr)
+			}
+			step.name = name
+		case strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")"):
+			filter, err := parseJSONPathFilter(inner[2 : len(inner)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter in %s: %w", expr, err)
+			}
+			step.filter = filter
+		default:
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in %s", inner, expr)
+			}
+			step.index = &n
+		}
+		path = append(path, step)
+	}
+
+	return path, nil
+}
+
+// cutJSONPathName splits off a member name in dot notation.
+func cutJSONPathName(s string) (string, string) {
+	if strings.HasPrefix(s, "*") {
+		return "*", s[1:]
+	}
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:]
+}
+
+// matchingBracket returns the index of the ']' closing the '[' at s[0],
+// skipping brackets in quoted strings and nested filters.
+func matchingBracket(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func unquoteJSONPathString(s string) (string, error) {
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("unterminated string %s", s)
+	}
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `"`, `\"`), `\'`, `'`) + `"`
+	}
+	return strconv.Unquote(s)
+}
+
+func parseJSONPathFilter(expr string) (*jsonPathFilter, error) {
+	filter := &jsonPathFilter{}
+	left := expr
+	if i := filterOperator(expr); i >= 0 {
+		filter.op = expr[i : i+2]
+		left = expr[:i]
+		right := strings.TrimSpace(expr[i+2:])
+		switch {
+		case strings.HasPrefix(right, "'") || strings.HasPrefix(right, `"`):
+			value, err := unquoteJSONPathString(right)
+			if err != nil {
+				return nil, err
+			}
+			filter.value = value
+		default:
+			var value any
+			if err := json.Unmarshal([]byte(right), &value); err != nil {
+				return nil, fmt.Errorf("invalid value %s", right)
+			}
+			filter.value = value
+		}
+	}
+
+	left = strings.TrimSpace(left)
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter must test a path starting with '@': %s", expr)
+	}
+	path, err := parseJSONPath(left)
+	if err != nil {
+		return nil, err
+	}
+	filter.path = path
+	return filter, nil
+}
+
+// filterOperator returns the index of the first == or != in a filter
+// expression outside quoted strings, or -1 for an existence test.
+func filterOperator(expr string) int {
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case (c == '=' || c == '!') && i+1 < len(expr) && expr[i+1] == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// evaluate returns the values selected by the path, in document order.
+func (p jsonPath) evaluate(document any) []any {
+	nodes := []any{document}
+	for _, step := range p {
+		var next []any
+		for _, node := range nodes {
+			if step.recursive {
+				for _, descendant := range descendants(node) {
+					next = append(next, step.apply(descendant)...)
+				}
+			} else {
+				next = append(next, step.apply(node)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (s jsonPathStep) apply(node any) []any {
+	switch {
+	case s.wildcard:
+		return children(node)
+
+	case s.filter != nil:
+		var matches []any
+		for _, child := range children(node) {
+			if s.filter.matches(child) {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+
+	case s.index != nil:
+		list, ok := node.([]any)
+		if !ok {
+			return nil
+		}
+		i := *s.index
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil
+		}
+		return []any{list[i]}
+
+	default:
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		if value, ok := object[s.name]; ok {
+			return []any{value}
+		}
+		return nil
+	}
+}
+
+func (f *jsonPathFilter) matches(node any) bool {
+	values := f.path.evaluate(node)
+	if f.op == "" {
+		return len(values) > 0
+	}
+
+	equal := false
+	for _, value := range values {
+		if jsonEqual(value, f.value) {
+			equal = true
+			break
+		}
+	}
+	if f.op == "==" {
+		return equal
+	}
+	return len(values) > 0 && !equal
+}
+
+// jsonEqual compares a decoded document value with a filter literal.
+// Documents are decoded with json.Number, literals as float64.
+func jsonEqual(value, literal any) bool {
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		if err != nil {
+			return false
+		}
+		value = f
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return value == literal
+}
+
+// children returns the members of an object, sorted by key for a stable
+// order, or the elements of an array.
+func children(node any) []any {
+	switch node := node.(type) {
+	case []any:
+		return node
+	case map[string]any:
+		keys := make([]string, 0, len(node))
+		for key := range node {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]any, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, node[key])
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns node and all values nested in it.
+func descendants(node any) []any {
+	result := []any{node}
+	for _, child := range children(node) {
+		result = append(result, descendants(child)...)
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+const jsonPathDocument = `{
+	"name": "tool",
+	"dotted.key": "dotted",
+	"releases": [
+		{"version": "1.0.0", "channel": "stable", "build": 10},
+		{"version": "1.1.0-rc.1", "channel": "beta", "build": 11},
+		{"version": "1.1.0", "channel": "stable", "build": 12, "lts": true}
+	],
+	"latest": {"stable": "1.1.0", "beta": "1.1.0-rc.1"},
+	"nested": {"deep": {"version": "0.9.0"}},
+	"tags": [
+		{"name": "a==b"},
+		{"name": "c!=d"},
+		{"name": "keyed", "x==y": 1}
+	]
+}`
+
+func TestJSONPath(t *testing.T) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(jsonPathDocument)))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"$.name", []string{"tool"}},
+		{"$['dotted.key']", []string{"dotted"}},
+		{`$["name"]`, []string{"tool"}},
+		{"$.releases[0].version", []string{"1.0.0"}},
+		{"$.releases[-1].version", []string{"1.1.0"}},
+		{"$.releases[5].version", nil},
+		{"$.releases[*].version", []string{"1.0.0", "1.1.0-rc.1", "1.1.0"}},
+		{"$.latest.*", []string{"1.1.0-rc.1", "1.1.0"}},
+		{"$..version", []string{"0.9.0", "1.0.0", "1.1.0-rc.1", "1.1.0"}},
+		{"$.releases[?(@.channel == 'stable')].version", []string{"1.0.0", "1.1.0"}},
+		{`$.releases[?(@.channel != "stable")].version`, []string{"1.1.0-rc.1"}},
+		{"$.releases[?(@.build == 11)].version", []string{"1.1.0-rc.1"}},
+		{"$.releases[?(@.lts)].version", []string{"1.1.0"}},
+		{"$.releases[?(@.lts == true)].build", []string{"12"}},
+		{"$.missing.version", nil},
+		{"$.tags[?(@.name=='a==b')].name", []string{"a==b"}},
+		{`$.tags[?(@.name == "c!=d")].name`, []string{"c!=d"}},
+		{"$.tags[?(@.name != 'a==b')].name", []string{"c!=d", "keyed"}},
+		{"$.tags[?(@['x==y'] == 1)].name", []string{"keyed"}},
+		{"$.tags[?(@['x==y'])].name", []string{"keyed"}},
+	}
+
+	for _, tt := range tests {
+		path, err := parseJSONPath(tt.expr)
+		if err != nil {
+			t.Errorf("parseJSONPath(%q): %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, value := range path.evaluate(document) {
+			got = append(got, fmt.Sprint(value))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseJSONPathInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"releases",
+		"$.",
+		"$..",
+		"$.releases[0",
+		"$.releases[x]",
+		"$['name]",
+		"$[?(version == '1')]",
+		"$[?(@.a == nope)]",
+		"$[?(@.a != )]",
+	} {
+		if _, err := parseJSONPath(expr); err == nil {
+			t.Errorf("parseJSONPath(%q) succeeded, want error", expr)
+		}
+	}
+}
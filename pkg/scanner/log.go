@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+type logOutputKey struct{}
+
+// WithLogOutput returns a copy of ctx in which verbose output of the
+// scanner and its sources goes to w rather than stdout, so that the output
+// of concurrent scans can be kept apart.
+func WithLogOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, logOutputKey{}, w)
+}
+
+// logf prints verbose output to the writer set with WithLogOutput, or to
+// stdout if there is none.
+func logf(ctx context.Context, format string, args ...any) {
+	w, ok := ctx.Value(logOutputKey{}).(io.Writer)
+	if !ok {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, format, args...)
+}
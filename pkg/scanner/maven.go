@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const defaultMavenRepository = "https://repo.maven.apache.org/maven2"
+
+// MavenScanner lists the versions of an artifact from the
+// maven-metadata.xml of a Maven repository such as Maven Central, Nexus or
+// Artifactory.
+type MavenScanner struct {
+	verbose bool
+}
+
+type mavenMetadata struct {
+	Versions []string `xml:"versioning>versions>version"`
+}
+
+func init() {
+	RegisterSource("maven", func(verbose bool) Source {
+		return NewMavenScanner(verbose)
+	})
+}
+
+func NewMavenScanner(verbose bool) *MavenScanner {
+	return &MavenScanner{verbose: verbose}
+}
+
+func (m *MavenScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	if repo.Maven == nil || repo.Maven.GroupID == "" || repo.Maven.ArtifactID == "" {
+		return nil, Permanent(fmt.Errorf("no artifact configured: set maven.groupId and maven.artifactId"))
+	}
+
+	baseURL := strings.TrimSuffix(repo.URL, "/")
+	if baseURL == "" {
+		baseURL = defaultMavenRepository
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataURL := fmt.Sprintf("%s/%s/%s/maven-metadata.xml",
+		baseURL, strings.ReplaceAll(repo.Maven.GroupID, ".", "/"), repo.Maven.ArtifactID)
+
+	body, err := httpGetBody(ctx, metadataURL, header, m.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download maven metadata: %w", err)
+	}
+
+	var metadata mavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse maven metadata %s: %w", metadataURL, err)
+	}
+
+	versions := make([]string, 0, len(metadata.Versions))
+	for _, v := range metadata.Versions {
+		versions = append(versions, strings.TrimSpace(v))
+	}
+	return versions, nil
+}
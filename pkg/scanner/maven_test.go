@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestMaven(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/maven2/org/hibernate/orm/hibernate-core/maven-metadata.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "deploy" || password != "secret" {
+			t.Errorf("credentials = %q, %q, want deploy, secret", user, password)
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>org.hibernate.orm</groupId>
+  <artifactId>hibernate-core</artifactId>
+  <versioning>
+    <latest>6.4.4.Final</latest>
+    <release>6.4.4.Final</release>
+    <versions>
+      <version>6.4.3.Final</version>
+      <version> 6.4.4.Final </version>
+      <version>7.0.0.Beta1</version>
+    </versions>
+  </versioning>
+</metadata>`)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_MAVEN_PASSWORD", "secret")
+
+	repo := &config.Repository{
+		Type:  "maven",
+		URL:   server.URL + "/maven2/",
+		Auth:  &config.Auth{Type: "basic", EnvVariable: "TEST_MAVEN_PASSWORD", Username: "deploy"},
+		Maven: &config.Maven{GroupID: "org.hibernate.orm", ArtifactID: "hibernate-core"},
+	}
+	versions, err := NewMavenScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"6.4.3.Final", "6.4.4.Final", "7.0.0.Beta1"}; !slices.Equal(versions, want) {
+		t.Errorf("ListVersions() = %v, want %v", versions, want)
+	}
+
+	repo.Maven.ArtifactID = "missing"
+	if _, err := NewMavenScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() of a missing artifact error = %v, want a permanent error", err)
+	}
+}
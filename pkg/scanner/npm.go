@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const defaultNPMRegistry = "https://registry.npmjs.org"
+
+// NPMScanner lists the published versions of an npm package.
+type NPMScanner struct {
+	verbose bool
+}
+
+type npmPackument struct {
+	DistTags map[string]string          `json:"dist-tags"`
+	Versions map[string]json.RawMessage `json:"versions"`
+}
+
+func init() {
+	RegisterSource("npm", func(verbose bool) Source {
+		return NewNPMScanner(verbose)
+	})
+}
+
+func NewNPMScanner(verbose bool) *NPMScanner {
+	return &NPMScanner{verbose: verbose}
+}
+
+func (n *NPMScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.NPM
+	if options == nil {
+		options = &config.NPM{}
+	}
+
+	name := strings.TrimSpace(repo.URL)
+	if name == "" {
+		return nil, Permanent(fmt.Errorf("no npm package configured: set url to the package name"))
+	}
+
+	registry := strings.TrimSuffix(options.Registry, "/")
+	if registry == "" {
+		registry = defaultNPMRegistry
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+	// The abbreviated document only carries what installers need, which
+	// includes versions and dist-tags
+	header.Set("Accept", "application/vnd.npm.install-v1+json")
+
+	// Scoped packages are requested as @scope%2Fname
+	packageURL := registry + "/" + strings.Replace(name, "/", "%2F", 1)
+
+	var packument npmPackument
+	if _, err := httpGetJSON(ctx, packageURL, header, n.verbose, &packument); err != nil {
+		return nil, fmt.Errorf("failed to fetch npm package: %w", err)
+	}
+
+	if options.DistTag != "" {
+		tagged, ok := packument.DistTags[options.DistTag]
+		if !ok {
+			return nil, Permanent(fmt.Errorf("dist-tag '%s' not found for package %s", options.DistTag, name))
+		}
+		if n.verbose {
+			logf(ctx, "dist-tag '%s' points to %s\n", options.DistTag, tagged)
+		}
+		return []string{tagged}, nil
+	}
+
+	versions := make([]string, 0, len(packument.Versions))
+	for v := range packument.Versions {
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
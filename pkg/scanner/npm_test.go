@@ -0,0 +1,63 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestNPM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/@scope%2Fpkg" {
+			http.NotFound(w, r)
+			return
+		}
+		if got, want := r.Header.Get("Accept"), "application/vnd.npm.install-v1+json"; got != want {
+			t.Errorf("Accept = %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{
+			"name": "@scope/pkg",
+			"dist-tags": {"latest": "1.1.0", "next": "2.0.0-beta.1"},
+			"versions": {"1.0.0": {}, "1.1.0": {}, "2.0.0-beta.1": {}}
+		}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		distTag string
+		want    []string
+	}{
+		{"", []string{"1.0.0", "1.1.0", "2.0.0-beta.1"}},
+		{"next", []string{"2.0.0-beta.1"}},
+	}
+
+	for _, tt := range tests {
+		repo := &config.Repository{
+			Type: "npm",
+			URL:  "@scope/pkg",
+			NPM:  &config.NPM{Registry: server.URL + "/", DistTag: tt.distTag},
+		}
+		versions, err := NewNPMScanner(false).ListVersions(context.Background(), repo)
+		if err != nil {
+			t.Fatalf("dist-tag %q: %v", tt.distTag, err)
+		}
+		slices.Sort(versions)
+		if !slices.Equal(versions, tt.want) {
+			t.Errorf("dist-tag %q: ListVersions() = %v, want %v", tt.distTag, versions, tt.want)
+		}
+	}
+
+	repo := &config.Repository{
+		Type: "npm",
+		URL:  "@scope/pkg",
+		NPM:  &config.NPM{Registry: server.URL, DistTag: "canary"},
+	}
+	if _, err := NewNPMScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() of a missing dist-tag error = %v, want a permanent error", err)
+	}
+}
@@ -0,0 +1,241 @@
+package scanner
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+	// defaultRegistryUsername is sent with a token when no username is
+	// configured; registries such as GHCR accept any non-empty name.
+	defaultRegistryUsername = "token"
+)
+
+// OCIScanner lists the tags of a container image from any registry that
+// implements the OCI Distribution API, including Docker Hub and GHCR.
+type OCIScanner struct {
+	verbose bool
+}
+
+// ociReference identifies a repository in a registry.
+type ociReference struct {
+	// Scheme is "https" unless the reference was given as http://...
+	Scheme     string
+	Registry   string
+	Repository string
+}
+
+type ociTagList struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type ociToken struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+func init() {
+	factory := func(verbose bool) Source {
+		return NewOCIScanner(verbose)
+	}
+	RegisterSource("oci", factory)
+	RegisterSource("docker", factory)
+}
+
+func NewOCIScanner(verbose bool) *OCIScanner {
+	return &OCIScanner{verbose: verbose}
+}
+
+func (o *OCIScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	ref, err := parseOCIReference(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	return o.listTags(ctx, repo, ref)
+}
+
+// parseOCIReference parses image references such as "nginx",
+// "ghcr.io/owner/image", "localhost:5000/image:tag" or
+// "oci://registry.example.com/charts/app". References starting with http://
+// are accessed without TLS, which is useful for a local registry.
+func parseOCIReference(raw string) (ociReference, error) {
+	ref := ociReference{Scheme: "https"}
+
+	name := raw
+	if i := strings.Index(name, "://"); i >= 0 {
+		switch name[:i] {
+		case "http":
+			ref.Scheme = "http"
+		case "https", "oci", "docker":
+		default:
+			return ref, Permanent(fmt.Errorf("unsupported image reference scheme: %s", name[:i]))
+		}
+		name = name[i+3:]
+	}
+	name = strings.Trim(name, "/")
+
+	// Drop a digest or tag, the tag separator being a colon after the last
+	// slash so that registry ports are kept
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+
+	if name == "" {
+		return ref, Permanent(fmt.Errorf("invalid image reference: %q", raw))
+	}
+
+	// The first component is a registry host if it looks like one
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry = first
+		ref.Repository = rest
+	} else {
+		ref.Registry = "docker.io"
+		ref.Repository = name
+	}
+
+	if ref.Registry == "docker.io" || ref.Registry == "index.docker.io" {
+		ref.Registry = dockerHubRegistry
+		if !strings.Contains(ref.Repository, "/") {
+			// Official images live in the library namespace
+			ref.Repository = "library/" + ref.Repository
+		}
+	}
+
+	return ref, nil
+}
+
+// listTags pages through /v2/<name>/tags/list, answering a registry's
+// authentication challenge with the credentials configured in repo.Auth.
+func (o *OCIScanner) listTags(ctx context.Context, repo *config.Repository, ref ociReference) ([]string, error) {
+	username, password, err := o.credentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	authorized := false
+
+	var tags []string
+	next := fmt.Sprintf("%s://%s/v2/%s/tags/list?n=1000", ref.Scheme, ref.Registry, ref.Repository)
+	for next != "" {
+		var page ociTagList
+		respHeader, err := httpGetJSON(ctx, next, header, o.verbose, &page)
+
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized && !authorized {
+			authorization, err := o.authorize(ctx, ref, statusErr.Header.Get("WWW-Authenticate"), username, password)
+			if err != nil {
+				return nil, err
+			}
+			header.Set("Authorization", authorization)
+			authorized = true
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list image tags: %w", err)
+		}
+
+		tags = append(tags, page.Tags...)
+
+		next, err = nextPage(next, respHeader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+func (o *OCIScanner) credentials(repo *config.Repository) (string, string, error) {
+	password, err := authToken(repo)
+	if err != nil || password == "" {
+		return "", "", err
+	}
+
+	switch repo.Auth.Type {
+	case "", "token", "basic":
+	default:
+		return "", "", Permanent(fmt.Errorf("unsupported authentication type for %s repositories: %s", repo.Type, repo.Auth.Type))
+	}
+
+	username := repo.Auth.Username
+	if username == "" {
+		username = defaultRegistryUsername
+	}
+	return username, password, nil
+}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authorize returns the Authorization header value answering a
+// WWW-Authenticate challenge. Bearer challenges are exchanged for a token at
+// the advertised realm, anonymously if no credentials are configured.
+func (o *OCIScanner) authorize(ctx context.Context, ref ociReference, challenge, username, password string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if password == "" {
+			return "", Permanent(fmt.Errorf("registry %s requires authentication", ref.Registry))
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+
+	case "bearer":
+		values := map[string]string{}
+		for _, match := range challengeParamRegex.FindAllStringSubmatch(params, -1) {
+			values[strings.ToLower(match[1])] = match[2]
+		}
+		if values["realm"] == "" {
+			return "", Permanent(fmt.Errorf("registry %s sent a bearer challenge without realm", ref.Registry))
+		}
+
+		tokenURL, err := url.Parse(values["realm"])
+		if err != nil {
+			return "", Permanent(fmt.Errorf("invalid token realm %q: %w", values["realm"], err))
+		}
+		query := tokenURL.Query()
+		if values["service"] != "" {
+			query.Set("service", values["service"])
+		}
+		scope := values["scope"]
+		if scope == "" {
+			scope = fmt.Sprintf("repository:%s:pull", ref.Repository)
+		}
+		query.Set("scope", scope)
+		tokenURL.RawQuery = query.Encode()
+
+		header := http.Header{}
+		if password != "" {
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+		}
+
+		var token ociToken
+		if _, err := httpGetJSON(ctx, tokenURL.String(), header, o.verbose, &token); err != nil {
+			return "", fmt.Errorf("failed to obtain registry token: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		if token.Token == "" {
+			return "", Permanent(fmt.Errorf("registry %s returned an empty token", ref.Registry))
+		}
+		return "Bearer " + token.Token, nil
+
+	default:
+		return "", Permanent(fmt.Errorf("registry %s requires unsupported authentication: %q", ref.Registry, challenge))
+	}
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want ociReference
+	}{
+		{"nginx", ociReference{"https", dockerHubRegistry, "library/nginx"}},
+		{"nginx:1.25", ociReference{"https", dockerHubRegistry, "library/nginx"}},
+		{"docker.io/bitnami/redis", ociReference{"https", dockerHubRegistry, "bitnami/redis"}},
+		{"ghcr.io/owner/image@sha256:abc", ociReference{"https", "ghcr.io", "owner/image"}},
+		{"localhost:5000/image:tag", ociReference{"https", "localhost:5000", "image"}},
+		{"localhost/image", ociReference{"https", "localhost", "image"}},
+		{"oci://registry.example.com/charts/app", ociReference{"https", "registry.example.com", "charts/app"}},
+		{"http://127.0.0.1:5000/team/app/", ociReference{"http", "127.0.0.1:5000", "team/app"}},
+		{"owner/image", ociReference{"https", dockerHubRegistry, "owner/image"}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOCIReference(tt.raw)
+		if err != nil {
+			t.Errorf("parseOCIReference(%q): %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseOCIReference(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+
+	for _, raw := range []string{"", "ftp://example.com/image", "oci://"} {
+		if _, err := parseOCIReference(raw); err == nil || IsTransient(err) {
+			t.Errorf("parseOCIReference(%q) error = %v, want a permanent error", raw, err)
+		}
+	}
+}
+
+func TestOCIBearerAuth(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "robot" || password != "secret" {
+				t.Errorf("token request credentials = %q, %q, want robot, secret", user, password)
+			}
+			if got, want := r.URL.Query().Get("scope"), "repository:team/app:pull"; got != want {
+				t.Errorf("token scope = %q, want %q", got, want)
+			}
+			if got, want := r.URL.Query().Get("service"), "registry.test"; got != want {
+				t.Errorf("token service = %q, want %q", got, want)
+			}
+			fmt.Fprint(w, `{"access_token": "registry-token"}`)
+
+		case "/v2/team/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer registry-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.test"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("last") == "1.0" {
+				fmt.Fprint(w, `{"name": "team/app", "tags": ["1.1", "latest"]}`)
+				return
+			}
+			// Registries send relative pagination links
+			w.Header().Set("Link", `</v2/team/app/tags/list?n=1000&last=1.0>; rel="next"`)
+			fmt.Fprint(w, `{"name": "team/app", "tags": ["0.9", "1.0"]}`)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEST_REGISTRY_TOKEN", "secret")
+
+	repo := &config.Repository{
+		Type: "oci",
+		URL:  server.URL + "/team/app",
+		Auth: &config.Auth{Type: "basic", EnvVariable: "TEST_REGISTRY_TOKEN", Username: "robot"},
+	}
+	tags, err := NewOCIScanner(false).ListVersions(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0.9", "1.0", "1.1", "latest"}; !slices.Equal(tags, want) {
+		t.Errorf("ListVersions() = %v, want %v", tags, want)
+	}
+}
+
+func TestOCIForeignNextLink(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request to foreign host with Authorization %q", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"tags": []}`)
+	}))
+	defer foreign.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/v2/team/app/tags/list?last=1.0>; rel="next"`, foreign.URL))
+		fmt.Fprint(w, `{"name": "team/app", "tags": ["1.0"]}`)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_REGISTRY_TOKEN", "secret")
+
+	repo := &config.Repository{
+		Type: "oci",
+		URL:  server.URL + "/team/app",
+		Auth: &config.Auth{Type: "basic", EnvVariable: "TEST_REGISTRY_TOKEN", Username: "robot"},
+	}
+	if _, err := NewOCIScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+		t.Errorf("ListVersions() error = %v, want a permanent error", err)
+	}
+}
+
+func TestOCIAuthorize(t *testing.T) {
+	o := NewOCIScanner(false)
+	ref := ociReference{"https", "registry.test", "team/app"}
+
+	got, err := o.authorize(context.Background(), ref, `Basic realm="registry"`, "user", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Basic dXNlcjpwYXNz"; got != want {
+		t.Errorf("authorize() = %q, want %q", got, want)
+	}
+
+	for _, challenge := range []string{
+		`Basic realm="registry"`,
+		`Bearer service="registry.test"`,
+		`Negotiate`,
+	} {
+		_, err := o.authorize(context.Background(), ref, challenge, "", "")
+		if err == nil || IsTransient(err) {
+			t.Errorf("authorize(%q) error = %v, want a permanent error", challenge, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "registry.test") {
+			t.Errorf("authorize(%q) error = %v, want it to name the registry", challenge, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const (
+	defaultPyPIJSONIndex   = "https://pypi.org/pypi"
+	defaultPyPISimpleIndex = "https://pypi.org/simple"
+)
+
+// PyPIScanner lists the releases of a Python project from PyPI or another
+// package index. Releases whose files have all been yanked are skipped.
+type PyPIScanner struct {
+	verbose bool
+}
+
+type pypiFile struct {
+	Filename string `json:"filename"`
+	// Yanked is a bool in the JSON API and a bool or reason string in the
+	// simple API
+	Yanked any `json:"yanked"`
+}
+
+func (f pypiFile) isYanked() bool {
+	switch yanked := f.Yanked.(type) {
+	case bool:
+		return yanked
+	case string:
+		return true
+	default:
+		return false
+	}
+}
+
+type pypiJSONProject struct {
+	Releases map[string][]pypiFile `json:"releases"`
+}
+
+type pypiSimpleProject struct {
+	Versions []string   `json:"versions"`
+	Files    []pypiFile `json:"files"`
+}
+
+func init() {
+	RegisterSource("pypi", func(verbose bool) Source {
+		return NewPyPIScanner(verbose)
+	})
+}
+
+func NewPyPIScanner(verbose bool) *PyPIScanner {
+	return &PyPIScanner{verbose: verbose}
+}
+
+var pypiNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName normalises a project name as described in PEP 503.
+func normalizePyPIName(name string) string {
+	return strings.ToLower(pypiNameSeparators.ReplaceAllString(name, "-"))
+}
+
+func (p *PyPIScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.PyPI
+	if options == nil {
+		options = &config.PyPI{}
+	}
+
+	name := normalizePyPIName(strings.TrimSpace(repo.URL))
+	if name == "" {
+		return nil, Permanent(fmt.Errorf("no PyPI project configured: set url to the project name"))
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	switch options.API {
+	case "", "json":
+		index := strings.TrimSuffix(options.Index, "/")
+		if index == "" {
+			index = defaultPyPIJSONIndex
+		}
+		return p.listJSON(ctx, fmt.Sprintf("%s/%s/json", index, name), header)
+
+	case "simple":
+		index := strings.TrimSuffix(options.Index, "/")
+		if index == "" {
+			index = defaultPyPISimpleIndex
+		}
+		header.Set("Accept", "application/vnd.pypi.simple.v1+json")
+		return p.listSimple(ctx, fmt.Sprintf("%s/%s/", index, name), name, header)
+
+	default:
+		return nil, Permanent(fmt.Errorf("unsupported PyPI API: %s", options.API))
+	}
+}
+
+func (p *PyPIScanner) listJSON(ctx context.Context, projectURL string, header http.Header) ([]string, error) {
+	var project pypiJSONProject
+	if _, err := httpGetJSON(ctx, projectURL, header, p.verbose, &project); err != nil {
+		return nil, fmt.Errorf("failed to fetch PyPI project: %w", err)
+	}
+
+	var versions []string
+	for v, files := range project.Releases {
+		if p.allYanked(ctx, v, files) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (p *PyPIScanner) listSimple(ctx context.Context, projectURL, name string, header http.Header) ([]string, error) {
+	var project pypiSimpleProject
+	if _, err := httpGetJSON(ctx, projectURL, header, p.verbose, &project); err != nil {
+		return nil, fmt.Errorf("failed to fetch PyPI project: %w", err)
+	}
+
+	// Group files by the version encoded in their name, which is the only
+	// source of versions for indexes that predate PEP 700
+	filesByVersion := map[string][]pypiFile{}
+	for _, file := range project.Files {
+		if v := pypiFileVersion(file.Filename, name); v != "" {
+			filesByVersion[v] = append(filesByVersion[v], file)
+		}
+	}
+
+	candidates := project.Versions
+	if len(candidates) == 0 {
+		for v := range filesByVersion {
+			candidates = append(candidates, v)
+		}
+	}
+
+	var versions []string
+	for _, v := range candidates {
+		if files, ok := filesByVersion[v]; ok && p.allYanked(ctx, v, files) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (p *PyPIScanner) allYanked(ctx context.Context, version string, files []pypiFile) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, file := range files {
+		if !file.isYanked() {
+			return false
+		}
+	}
+	if p.verbose {
+		logf(ctx, "Ignoring yanked release '%s'\n", version)
+	}
+	return true
+}
+
+var sdistExtensions = []string{".tar.gz", ".tar.bz2", ".tgz", ".zip"}
+
+// pypiFileVersion extracts the version from a wheel or sdist file name of
+// the normalised project name, or returns "" if the name is not recognised.
+func pypiFileVersion(filename, name string) string {
+	if base, ok := strings.CutSuffix(filename, ".whl"); ok {
+		// {name}-{version}(-{build})?-{python}-{abi}-{platform}.whl
+		parts := strings.Split(base, "-")
+		if len(parts) >= 5 {
+			return parts[1]
+		}
+		return ""
+	}
+
+	for _, ext := range sdistExtensions {
+		if base, ok := strings.CutSuffix(filename, ext); ok {
+			// {name}-{version}.tar.gz, where the name may contain dashes
+			i := strings.LastIndex(base, "-")
+			if i < 0 || normalizePyPIName(base[:i]) != name {
+				return ""
+			}
+			return base[i+1:]
+		}
+	}
+	return ""
+}
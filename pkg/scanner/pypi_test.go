@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestPyPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pypi/zope-interface/json":
+			fmt.Fprint(w, `{"releases": {
+				"5.0": [{"filename": "zope.interface-5.0.tar.gz", "yanked": false}],
+				"5.1": [{"filename": "zope.interface-5.1.tar.gz", "yanked": true}],
+				"6.0rc1": []
+			}}`)
+		case "/simple/zope-interface/":
+			if got, want := r.Header.Get("Accept"), "application/vnd.pypi.simple.v1+json"; got != want {
+				t.Errorf("Accept = %q, want %q", got, want)
+			}
+			// No PEP 700 versions list, so versions come from the file names
+			fmt.Fprint(w, `{"files": [
+				{"filename": "zope.interface-5.0.tar.gz"},
+				{"filename": "zope.interface-5.0-cp311-cp311-manylinux_2_17_x86_64.whl"},
+				{"filename": "zope.interface-5.1.tar.gz", "yanked": "broken build"},
+				{"filename": "zope_interface-6.0-py3-none-any.whl"},
+				{"filename": "other-9.0.tar.gz"}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		api   string
+		index string
+		want  []string
+	}{
+		{"json", server.URL + "/pypi/", []string{"5.0", "6.0rc1"}},
+		{"simple", server.URL + "/simple", []string{"5.0", "6.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.api, func(t *testing.T) {
+			repo := &config.Repository{
+				Type: "pypi",
+				URL:  "Zope.Interface",
+				PyPI: &config.PyPI{API: tt.api, Index: tt.index},
+			}
+			versions, err := NewPyPIScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(versions)
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
+
+func TestPyPIFileVersion(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		want     string
+	}{
+		{"requests-2.31.0.tar.gz", "requests", "2.31.0"},
+		{"requests-2.31.0-py3-none-any.whl", "requests", "2.31.0"},
+		{"my-pkg-1.0.post1.zip", "my-pkg", "1.0.post1"},
+		{"other-1.0.tar.gz", "requests", ""},
+		{"requests-2.31.0.exe", "requests", ""},
+	}
+	for _, tt := range tests {
+		if got := pypiFileVersion(tt.filename, tt.name); got != tt.want {
+			t.Errorf("pypiFileVersion(%q, %q) = %q, want %q", tt.filename, tt.name, got, tt.want)
+		}
+	}
+}
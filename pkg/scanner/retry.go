@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const (
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// PermanentError marks a failure that retrying cannot fix, such as rejected
+// credentials or a repository that does not exist.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so that it is not retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsTransient reports whether err may succeed when retried. Errors are
+// considered transient unless they are marked permanent or the context was
+// cancelled.
+func IsTransient(err error) bool {
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// listVersionsWithRetry calls source.ListVersions until it succeeds, fails
+// permanently or runs out of attempts, and returns the number of attempts
+// made.
+func (s *Scanner) listVersionsWithRetry(ctx context.Context, source Source, repo *config.Repository) ([]string, int, error) {
+	maxAttempts := 1
+	initialBackoff := defaultInitialBackoff
+	maxBackoff := defaultMaxBackoff
+	var jitter float64
+
+	if repo.Retry != nil {
+		if repo.Retry.MaxAttempts > 0 {
+			maxAttempts = repo.Retry.MaxAttempts
+		}
+		if repo.Retry.InitialBackoff > 0 {
+			initialBackoff = time.Duration(repo.Retry.InitialBackoff)
+		}
+		if repo.Retry.MaxBackoff > 0 {
+			maxBackoff = time.Duration(repo.Retry.MaxBackoff)
+		}
+		jitter = repo.Retry.Jitter
+	}
+
+	for attempt := 1; ; attempt++ {
+		tags, err := source.ListVersions(ctx, repo)
+		if err == nil {
+			return tags, attempt, nil
+		}
+		if attempt >= maxAttempts || !IsTransient(err) {
+			return nil, attempt, err
+		}
+
+		delay := retryDelay(attempt, initialBackoff, maxBackoff, jitter)
+		if s.verbose {
+			logf(ctx, "Attempt %d/%d for %s failed: %v (retrying in %s)\n", attempt, maxAttempts, repo.Name, err, delay.Round(time.Millisecond))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, fmt.Errorf("%w (gave up retrying: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay returns the delay after the given failed attempt: the initial
+// backoff doubled for each earlier attempt up to maxBackoff, randomly
+// shortened by up to the jitter fraction.
+func retryDelay(attempt int, initialBackoff, maxBackoff time.Duration, jitter float64) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxBackoff)
+	if jitter > 0 {
+		delay -= time.Duration(jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
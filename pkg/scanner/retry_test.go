@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+func TestRetryDelay(t *testing.T) {
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range want {
+		if got := retryDelay(i+1, time.Second, 5*time.Second, 0); got != delay {
+			t.Errorf("retryDelay(%d) = %s, want %s", i+1, got, delay)
+		}
+	}
+
+	// Jitter shortens the delay by up to the given fraction
+	for i := 0; i < 100; i++ {
+		got := retryDelay(3, time.Second, time.Minute, 0.25)
+		if got < 3*time.Second || got > 4*time.Second {
+			t.Fatalf("retryDelay(3) with jitter 0.25 = %s, want between 3s and 4s", got)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("connection reset"), true},
+		{&HTTPStatusError{URL: "https://example.com", StatusCode: 502}, true},
+		{Permanent(errors.New("bad credentials")), false},
+		{fmt.Errorf("listing tags: %w", Permanent(errors.New("bad credentials"))), false},
+		{fmt.Errorf("listing tags: %w", context.Canceled), false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	cause := errors.New("bad credentials")
+	if err := fmt.Errorf("listing tags: %w", Permanent(cause)); !errors.Is(err, cause) {
+		t.Errorf("errors.Is(%v, cause) = false, want true", err)
+	}
+	if Permanent(nil) != nil {
+		t.Errorf("Permanent(nil) != nil")
+	}
+}
+
+// flakySource fails with the given errors before succeeding.
+type flakySource struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakySource) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return []string{"v1.0.0"}, nil
+}
+
+func TestListVersionsWithRetry(t *testing.T) {
+	transient := errors.New("connection reset")
+	permanent := Permanent(errors.New("repository not found"))
+
+	tests := []struct {
+		name         string
+		errs         []error
+		maxAttempts  int
+		wantAttempts int
+		wantErr      error
+	}{
+		{"success", nil, 3, 1, nil},
+		{"retried until success", []error{transient, transient}, 3, 3, nil},
+		{"out of attempts", []error{transient, transient, transient}, 3, 3, transient},
+		{"permanent", []error{permanent}, 3, 1, permanent},
+		{"no retry by default", []error{transient}, 0, 1, transient},
+	}
+
+	s := &Scanner{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &flakySource{errs: tt.errs}
+			repo := &config.Repository{
+				Name:  "repo",
+				Retry: &config.Retry{MaxAttempts: tt.maxAttempts, InitialBackoff: config.Duration(time.Millisecond)},
+			}
+			_, attempts, err := s.listVersionsWithRetry(context.Background(), source, repo)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts || source.calls != tt.wantAttempts {
+				t.Errorf("attempts = %d (%d calls), want %d", attempts, source.calls, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestListVersionsWithRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	transient := errors.New("connection reset")
+	source := &flakySource{errs: []error{transient, transient}}
+	repo := &config.Repository{
+		Name:  "repo",
+		Retry: &config.Retry{MaxAttempts: 3, InitialBackoff: config.Duration(time.Hour)},
+	}
+
+	start := time.Now()
+	_, attempts, err := (&Scanner{}).listVersionsWithRetry(ctx, source, repo)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("listVersionsWithRetry() took %s, want it to stop waiting at the deadline", elapsed)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+	if !errors.Is(err, transient) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want the last failure and the deadline", err)
+	}
+}
@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// RPMScanner lists the EVRs of a package in the primary metadata of a
+// YUM/DNF repository.
+type RPMScanner struct {
+	verbose bool
+}
+
+type rpmRepomd struct {
+	Data []struct {
+		Type     string `xml:"type,attr"`
+		Location struct {
+			Href string `xml:"href,attr"`
+		} `xml:"location"`
+	} `xml:"data"`
+}
+
+type rpmPackage struct {
+	Name    string `xml:"name"`
+	Arch    string `xml:"arch"`
+	Version struct {
+		Epoch string `xml:"epoch,attr"`
+		Ver   string `xml:"ver,attr"`
+		Rel   string `xml:"rel,attr"`
+	} `xml:"version"`
+}
+
+func init() {
+	RegisterSource("rpm", func(verbose bool) Source {
+		return NewRPMScanner(verbose)
+	})
+}
+
+func NewRPMScanner(verbose bool) *RPMScanner {
+	return &RPMScanner{verbose: verbose}
+}
+
+func (r *RPMScanner) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	options := repo.RPM
+	if options == nil || options.Package == "" {
+		return nil, Permanent(fmt.Errorf("no package configured: set rpm.package"))
+	}
+
+	header, err := httpAuthHeader(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimSuffix(repo.URL, "/")
+
+	var repomd rpmRepomd
+	repomdURL := baseURL + "/repodata/repomd.xml"
+	body, err := httpGetBody(ctx, repomdURL, header, r.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download repository metadata: %w", err)
+	}
+	if err := xml.Unmarshal(body, &repomd); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", repomdURL, err)
+	}
+
+	primaryHref := ""
+	for _, data := range repomd.Data {
+		if data.Type == "primary" {
+			primaryHref = data.Location.Href
+			break
+		}
+	}
+	if primaryHref == "" {
+		return nil, Permanent(fmt.Errorf("no primary metadata listed in %s", repomdURL))
+	}
+
+	return r.listPrimary(ctx, baseURL+"/"+primaryHref, header, options)
+}
+
+// listPrimary streams primary.xml(.gz), which can be hundreds of megabytes
+// for distribution repositories, and collects the EVRs of the package.
+func (r *RPMScanner) listPrimary(ctx context.Context, primaryURL string, header http.Header, options *config.RPM) ([]string, error) {
+	resp, err := httpGet(ctx, primaryURL, header, r.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download primary metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var reader io.Reader = resp.Body
+	switch {
+	case strings.HasSuffix(primaryURL, ".gz"):
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", primaryURL, err)
+		}
+		defer gz.Close()
+		reader = gz
+	case strings.HasSuffix(primaryURL, ".xml"):
+	default:
+		return nil, Permanent(fmt.Errorf("unsupported compression of primary metadata: %s", primaryURL))
+	}
+
+	var versions []string
+	seen := map[string]bool{}
+
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("GET %s did not finish: %w", primaryURL, ctx.Err())
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", primaryURL, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "package" {
+			continue
+		}
+
+		var pkg rpmPackage
+		if err := decoder.DecodeElement(&pkg, &start); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", primaryURL, err)
+		}
+		if pkg.Name != options.Package {
+			continue
+		}
+		if options.Arch != "" && pkg.Arch != options.Arch && pkg.Arch != "noarch" {
+			continue
+		}
+
+		evr := pkg.Version.Ver
+		if pkg.Version.Rel != "" {
+			evr += "-" + pkg.Version.Rel
+		}
+		if pkg.Version.Epoch != "" && pkg.Version.Epoch != "0" {
+			evr = pkg.Version.Epoch + ":" + evr
+		}
+		if !seen[evr] {
+			seen[evr] = true
+			versions = append(versions, evr)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, Permanent(fmt.Errorf("package '%s' not found in %s", options.Package, primaryURL))
+	}
+	return versions, nil
+}
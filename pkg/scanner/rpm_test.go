@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+const rpmPrimaryXML = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata xmlns="http://linux.duke.edu/metadata/common" xmlns:rpm="http://linux.duke.edu/metadata/rpm" packages="5">
+<package type="rpm">
+  <name>openssl</name>
+  <arch>x86_64</arch>
+  <version epoch="1" ver="3.0.7" rel="25.el9"/>
+</package>
+<package type="rpm">
+  <name>openssl</name>
+  <arch>aarch64</arch>
+  <version epoch="1" ver="3.0.8" rel="1.el9"/>
+</package>
+<package type="rpm">
+  <name>openssl</name>
+  <arch>x86_64</arch>
+  <version epoch="0" ver="3.0.1" rel="1.el9"/>
+</package>
+<package type="rpm">
+  <name>openssl-libs</name>
+  <arch>x86_64</arch>
+  <version epoch="1" ver="9.9.9" rel="1.el9"/>
+</package>
+<package type="rpm">
+  <name>openssl</name>
+  <arch>x86_64</arch>
+  <version epoch="1" ver="3.0.7" rel="25.el9"/>
+</package>
+</metadata>`
+
+func TestRPM(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(rpmPrimaryXML))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gz/repodata/repomd.xml":
+			fmt.Fprint(w, `<repomd><data type="filelists"><location href="repodata/filelists.xml.gz"/></data>
+<data type="primary"><location href="repodata/abc-primary.xml.gz"/></data></repomd>`)
+		case "/gz/repodata/abc-primary.xml.gz":
+			w.Write(compressed.Bytes())
+		case "/plain/repodata/repomd.xml":
+			fmt.Fprint(w, `<repomd><data type="primary"><location href="repodata/primary.xml"/></data></repomd>`)
+		case "/plain/repodata/primary.xml":
+			fmt.Fprint(w, rpmPrimaryXML)
+		case "/zstd/repodata/repomd.xml":
+			fmt.Fprint(w, `<repomd><data type="primary"><location href="repodata/primary.xml.zst"/></data></repomd>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		options config.RPM
+		want    []string
+	}{
+		{"gzip", "/gz/", config.RPM{Package: "openssl"}, []string{"1:3.0.7-25.el9", "1:3.0.8-1.el9", "3.0.1-1.el9"}},
+		{"arch", "/plain", config.RPM{Package: "openssl", Arch: "x86_64"}, []string{"1:3.0.7-25.el9", "3.0.1-1.el9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{Type: "rpm", URL: server.URL + tt.path, RPM: &tt.options}
+			versions, err := NewRPMScanner(false).ListVersions(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("ListVersions() = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+
+	for _, options := range []struct {
+		path string
+		pkg  string
+	}{
+		{"/zstd", "openssl"},
+		{"/plain", "missing"},
+	} {
+		repo := &config.Repository{Type: "rpm", URL: server.URL + options.path, RPM: &config.RPM{Package: options.pkg}}
+		if _, err := NewRPMScanner(false).ListVersions(context.Background(), repo); err == nil || IsTransient(err) {
+			t.Errorf("ListVersions() of %s in %s error = %v, want a permanent error", options.pkg, options.path, err)
+		}
+	}
+}
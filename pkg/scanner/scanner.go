@@ -0,0 +1,366 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+	"github.com/wellcom-rocks/updates-sucks/pkg/version"
+)
+
+// Scanner resolves the latest version of a repository by asking the Source
+// registered for its type for candidate versions and filtering them
+// according to the repository's versioning configuration.
+type Scanner struct {
+	verbose bool
+	sources map[string]Source
+}
+
+func NewScanner(verbose bool) *Scanner {
+	s := &Scanner{
+		verbose: verbose,
+		sources: make(map[string]Source, len(sources)),
+	}
+	for repoType, factory := range sources {
+		s.sources[repoType] = factory(verbose)
+	}
+	return s
+}
+
+// Result describes the outcome of scanning a single repository.
+type Result struct {
+	LatestVersion string
+	// LatestInRange is the latest version allowed by the repository's
+	// update policy; empty if there is no policy or no version is allowed.
+	LatestInRange string
+	// Attempts is the number of times the source was queried, including
+	// retries of transient failures.
+	Attempts int
+}
+
+func (s *Scanner) Scan(ctx context.Context, repo *config.Repository) (Result, error) {
+	var result Result
+
+	source, ok := s.sources[repo.Type]
+	if !ok {
+		return result, fmt.Errorf("unsupported repository type: %s (supported: %s)", repo.Type, strings.Join(SourceTypes(), ", "))
+	}
+
+	tags, attempts, err := s.listVersionsWithRetry(ctx, source, repo)
+	result.Attempts = attempts
+	if err != nil {
+		return result, err
+	}
+
+	versions, tagOf, scheme, err := s.candidates(ctx, repo, tags)
+	if err != nil {
+		return result, err
+	}
+
+	latestVersion, err := s.findLatestVersionFromValidTags(versions, scheme)
+	if err != nil {
+		return result, fmt.Errorf("failed to find latest version: %w", err)
+	}
+	result.LatestVersion = tagOf[latestVersion]
+
+	if repo.UpdatePolicy != "" {
+		latestInRange, err := s.findLatestAllowedVersion(ctx, repo, versions, scheme)
+		if err != nil {
+			return result, err
+		}
+		result.LatestInRange = tagOf[latestInRange]
+	}
+
+	return result, nil
+}
+
+// findLatestAllowedVersion selects the latest of the valid versions allowed
+// by the repository's update policy. It returns "" if the policy allows
+// none of them, e.g. if there is no newer patch release.
+func (s *Scanner) findLatestAllowedVersion(ctx context.Context, repo *config.Repository, versions []string, scheme string) (string, error) {
+	policy, err := version.ParseUpdatePolicy(repo.UpdatePolicy)
+	if err != nil {
+		return "", err
+	}
+
+	current := ""
+	if policy.NeedsCurrent() {
+		current = VersionFromTag(repo.Versioning, repo.CurrentVersion)
+	}
+
+	var allowed []string
+	for _, v := range versions {
+		ok, err := policy.AllowsVersion(current, v, scheme)
+		if err != nil {
+			return "", fmt.Errorf("update policy '%s' requires a valid %s current version: %w", policy, scheme, err)
+		}
+		if ok {
+			allowed = append(allowed, v)
+		} else if s.verbose {
+			logf(ctx, "Ignoring version '%s' outside update policy '%s'\n", v, policy)
+		}
+	}
+	if len(allowed) == 0 {
+		return "", nil
+	}
+
+	return s.findLatestVersionFromValidTags(allowed, scheme)
+}
+
+// candidates applies the repository's versioning configuration to tags. It
+// returns the valid versions of the scheme, the tag of each version and the
+// scheme.
+func (s *Scanner) candidates(ctx context.Context, repo *config.Repository, tags []string) ([]string, map[string]string, string, error) {
+	if len(tags) == 0 {
+		return nil, nil, "", fmt.Errorf("no tags found in repository")
+	}
+
+	versioning := repo.Versioning
+	if versioning == nil {
+		versioning = &config.Versioning{}
+	}
+
+	filter, err := newTagFilter(versioning)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	// Map the versions back to their tags, which carry the prefix and
+	// whatever the include pattern did not capture
+	tagOf := make(map[string]string, len(tags))
+	var versions []string
+	for _, tag := range s.filterPatterns(ctx, tags, filter) {
+		v := filter.version(tag)
+
+		// Remove prefix if configured
+		if versioning.IgnorePrefix != "" {
+			var ok bool
+			if v, ok = strings.CutPrefix(v, versioning.IgnorePrefix); !ok {
+				continue
+			}
+		}
+
+		if _, seen := tagOf[v]; !seen {
+			tagOf[v] = tag
+			versions = append(versions, v)
+		}
+	}
+
+	// Filter and sort tags based on versioning scheme first
+	scheme := "semver"
+	if versioning.Scheme != "" {
+		scheme = versioning.Scheme
+	}
+
+	// Filter valid tags first, then apply suffix filtering
+	validTags := s.getValidTags(ctx, versions, scheme)
+
+	// Filter out tags with ignored suffixes if configured
+	if len(versioning.IgnoreSuffixes) > 0 {
+		validTags = s.filterSuffixes(ctx, validTags, versioning.IgnoreSuffixes)
+	}
+
+	return validTags, tagOf, scheme, nil
+}
+
+// VersionFromTag returns the version a tag stands for: the "version"
+// capture of the include pattern, if any, without the ignored prefix. Tags
+// that do not match the include pattern are returned without the prefix
+// only, so that current versions can be given either way.
+func VersionFromTag(versioning *config.Versioning, tag string) string {
+	if versioning == nil {
+		return tag
+	}
+	if filter, err := newTagFilter(versioning); err == nil {
+		tag = filter.version(tag)
+	}
+	return strings.TrimPrefix(tag, versioning.IgnorePrefix)
+}
+
+// tagFilter holds the compiled include and exclude patterns of a
+// repository's versioning configuration.
+type tagFilter struct {
+	include *regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func newTagFilter(versioning *config.Versioning) (*tagFilter, error) {
+	filter := &tagFilter{}
+	if versioning.IncludePattern != "" {
+		include, err := regexp.Compile(versioning.IncludePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versioning includePattern: %w", err)
+		}
+		filter.include = include
+	}
+	for _, pattern := range versioning.ExcludePatterns {
+		exclude, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versioning excludePatterns: %w", err)
+		}
+		filter.exclude = append(filter.exclude, exclude)
+	}
+	return filter, nil
+}
+
+// version returns the "version" capture of the include pattern, or tag
+// itself if there is none or the pattern does not match.
+func (f *tagFilter) version(tag string) string {
+	if f.include == nil {
+		return tag
+	}
+	group := f.include.SubexpIndex("version")
+	if group < 0 {
+		return tag
+	}
+	matches := f.include.FindStringSubmatch(tag)
+	if matches == nil || matches[group] == "" {
+		return tag
+	}
+	return matches[group]
+}
+
+// filterPatterns keeps the tags matching the include pattern and none of
+// the exclude patterns.
+func (s *Scanner) filterPatterns(ctx context.Context, tags []string, filter *tagFilter) []string {
+	if filter.include == nil && len(filter.exclude) == 0 {
+		return tags
+	}
+
+	var result []string
+	for _, tag := range tags {
+		if filter.include != nil && !filter.include.MatchString(tag) {
+			continue
+		}
+		excluded := false
+		for _, exclude := range filter.exclude {
+			if exclude.MatchString(tag) {
+				excluded = true
+				if s.verbose {
+					logf(ctx, "Ignoring tag '%s' due to exclude pattern '%s'\n", tag, exclude)
+				}
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func (s *Scanner) filterSuffixes(ctx context.Context, tags []string, ignoreSuffixes []string) []string {
+	var result []string
+	for _, tag := range tags {
+		shouldIgnore := false
+		for _, suffix := range ignoreSuffixes {
+			if containsSuffix(tag, suffix) {
+				shouldIgnore = true
+				if s.verbose {
+					logf(ctx, "Ignoring tag '%s' due to suffix '%s'\n", tag, suffix)
+				}
+				break
+			}
+		}
+		if !shouldIgnore {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// containsSuffix reports whether tag contains suffix as a separate word,
+// so that "rc" matches "1.0.0-rc1" and "1.0.0rc1" but not "1.0.0-src".
+func containsSuffix(tag, suffix string) bool {
+	if suffix == "" {
+		return false
+	}
+	isLetter := func(c byte) bool {
+		return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+
+	for offset := 0; ; {
+		i := strings.Index(tag[offset:], suffix)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(suffix)
+		startsWord := start == 0 || !isLetter(tag[start-1]) || !isLetter(suffix[0])
+		endsWord := end == len(tag) || !isLetter(tag[end]) || !isLetter(suffix[len(suffix)-1])
+		if startsWord && endsWord {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func (s *Scanner) getValidTags(ctx context.Context, tags []string, scheme string) []string {
+	switch scheme {
+	case "semver":
+		return version.FilterValidSemVer(tags)
+	case "calver":
+		return version.FilterValidCalVer(tags)
+	case "pep440":
+		return version.FilterValidPEP440(tags)
+	case "maven":
+		return version.FilterValidMaven(tags)
+	case "debian":
+		return version.FilterValidDebian(tags)
+	case "rpm":
+		return version.FilterValidRPM(tags)
+	case "loose":
+		validTags := version.FilterValidLoose(tags)
+		if s.verbose {
+			for _, tag := range validTags {
+				if v, err := version.ParseLoose(tag); err == nil && v.Normalized() {
+					logf(ctx, "Normalised version '%s' to '%s'\n", tag, v)
+				}
+			}
+		}
+		return validTags
+	case "string":
+		return tags // All tags are valid for string comparison
+	default:
+		return tags
+	}
+}
+
+func (s *Scanner) findLatestVersionFromValidTags(validTags []string, scheme string) (string, error) {
+	if len(validTags) == 0 {
+		return "", fmt.Errorf("no valid tags found after filtering")
+	}
+
+	switch scheme {
+	case "semver":
+		sorted := version.SortSemVer(validTags)
+		return sorted[len(sorted)-1], nil
+	case "calver":
+		sorted := version.SortCalVer(validTags)
+		return sorted[len(sorted)-1], nil
+	case "pep440":
+		sorted := version.SortPEP440(validTags)
+		return sorted[len(sorted)-1], nil
+	case "maven":
+		sorted := version.SortMaven(validTags)
+		return sorted[len(sorted)-1], nil
+	case "debian":
+		sorted := version.SortDebian(validTags)
+		return sorted[len(sorted)-1], nil
+	case "rpm":
+		sorted := version.SortRPM(validTags)
+		return sorted[len(sorted)-1], nil
+	case "loose":
+		sorted := version.SortLoose(validTags)
+		return sorted[len(sorted)-1], nil
+	case "string":
+		sorted := make([]string, len(validTags))
+		copy(sorted, validTags)
+		sort.Strings(sorted)
+		return sorted[len(sorted)-1], nil
+	default:
+		return "", fmt.Errorf("unsupported versioning scheme: %s", scheme)
+	}
+}
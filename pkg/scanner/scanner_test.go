@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// staticSource lists the comma-separated tags given as a repository's URL.
+type staticSource struct{}
+
+func init() {
+	RegisterSource("static", func(verbose bool) Source {
+		return staticSource{}
+	})
+}
+
+func (staticSource) ListVersions(ctx context.Context, repo *config.Repository) ([]string, error) {
+	return strings.Split(repo.URL, ","), nil
+}
+
+func TestContainsSuffix(t *testing.T) {
+	tests := []struct {
+		tag    string
+		suffix string
+		want   bool
+	}{
+		{"1.0.0-rc1", "-rc", true},
+		{"1.0.0-rc.1", "rc", true},
+		{"1.0.0rc1", "rc", true},
+		{"1.25-alpine3.18", "-alpine", true},
+		{"5.3-perl", "-perl", true},
+		{"1.0.0-CR1", "CR", true},
+		{"1.0.0-beta-2", "beta", true},
+		// Suffixes are matched anywhere in the tag, not only at its end
+		{"1.0.0-rc1-hotfix", "-rc", true},
+		// but not as part of a longer word, which strings.Contains did
+		{"1.0.0-src-fix", "-rc", false},
+		{"1.0.0-src", "rc", false},
+		{"1.0.0-betamax", "beta", false},
+		// and case-sensitively
+		{"1.0.0-Beta", "beta", false},
+		{"2.0.0.Final", "CR", false},
+		{"1.0.0", "", false},
+	}
+	for _, tt := range tests {
+		if got := containsSuffix(tt.tag, tt.suffix); got != tt.want {
+			t.Errorf("containsSuffix(%q, %q) = %v, want %v", tt.tag, tt.suffix, got, tt.want)
+		}
+	}
+}
+
+func TestScanVersioning(t *testing.T) {
+	tests := []struct {
+		name       string
+		tags       string
+		current    string
+		versioning *config.Versioning
+		want       string
+	}{
+		{
+			name:       "ignore suffixes",
+			tags:       "1.0.0,1.1.0-src-fix,1.2.0-rc1",
+			current:    "1.0.0",
+			versioning: &config.Versioning{Scheme: "semver", IgnoreSuffixes: []string{"-rc"}},
+			want:       "1.1.0-src-fix",
+		},
+		{
+			name:    "include pattern with version group",
+			tags:    "helm-chart-4.2.1,helm-chart-4.10.0,app-9.0.0,v5.0.0",
+			current: "helm-chart-4.2.1",
+			versioning: &config.Versioning{
+				Scheme:         "semver",
+				IncludePattern: `^helm-chart-(?P<version>.+)$`,
+			},
+			want: "helm-chart-4.10.0",
+		},
+		{
+			name:    "include pattern without version group",
+			tags:    "v1.0.0,v1.1.0,v2.0.0",
+			current: "v1.0.0",
+			versioning: &config.Versioning{
+				Scheme:         "semver",
+				IgnorePrefix:   "v",
+				IncludePattern: `^v1\.`,
+			},
+			want: "v1.1.0",
+		},
+		{
+			name:    "version group with prefix",
+			tags:    "release/v1.2.0,release/v1.3.0,v2.0.0",
+			current: "1.2.0",
+			versioning: &config.Versioning{
+				Scheme:         "semver",
+				IgnorePrefix:   "v",
+				IncludePattern: `^release/(?P<version>.+)$`,
+			},
+			want: "release/v1.3.0",
+		},
+		{
+			name:    "exclude patterns",
+			tags:    "1.0.0,1.1.0-alpha.1,1.2.0-beta,nightly-2.0.0,1.0.1",
+			current: "1.0.0",
+			versioning: &config.Versioning{
+				Scheme:          "semver",
+				ExcludePatterns: []string{"-(alpha|beta)", "^nightly-"},
+			},
+			want: "1.0.1",
+		},
+		{
+			name:    "exclude pattern applies to the full tag",
+			tags:    "helm-chart-4.2.1,helm-chart-4.3.0,helm-chart-broken-4.4.0",
+			current: "4.2.1",
+			versioning: &config.Versioning{
+				Scheme:          "semver",
+				IncludePattern:  `^helm-chart-(?:broken-)?(?P<version>.+)$`,
+				ExcludePatterns: []string{"broken"},
+			},
+			want: "helm-chart-4.3.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &config.Repository{
+				Name:           tt.name,
+				Type:           "static",
+				URL:            tt.tags,
+				CurrentVersion: tt.current,
+				Versioning:     tt.versioning,
+			}
+			result, err := NewScanner(false).Scan(context.Background(), repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.LatestVersion != tt.want {
+				t.Errorf("LatestVersion = %q, want %q", result.LatestVersion, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionFromTag(t *testing.T) {
+	versioning := &config.Versioning{
+		IgnorePrefix:   "v",
+		IncludePattern: `^release/(?P<version>.+)$`,
+	}
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"release/v1.2.0", "1.2.0"},
+		{"v1.2.0", "1.2.0"},
+		{"1.2.0", "1.2.0"},
+	}
+	for _, tt := range tests {
+		if got := VersionFromTag(versioning, tt.tag); got != tt.want {
+			t.Errorf("VersionFromTag(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+	if got := VersionFromTag(nil, "v1.2.0"); got != "v1.2.0" {
+		t.Errorf("VersionFromTag(nil, %q) = %q, want it unchanged", "v1.2.0", got)
+	}
+}
+
+func TestScanUpdatePolicy(t *testing.T) {
+	tests := []struct {
+		scheme  string
+		tags    string
+		current string
+		policy  string
+		want    string
+	}{
+		{"semver", "v1.0.0,v1.2.0,v1.3.0-rc.1,v2.0.0", "v1.0.0", "minor", "v1.2.0"},
+		{"pep440", "v2.31.0,v2.32.1,v3.0.0", "v2.31.0", "minor", "v2.32.1"},
+		{"calver", "v2024.05.1,v2024.05.3,v2024.06.0", "v2024.05.1", "patch", "v2024.05.3"},
+		{"maven", "v6.4.4.Final,v6.6.1.Final,v7.0.0.Final", "v6.4.4.Final", "^6.4", "v6.6.1.Final"},
+	}
+
+	for _, tt := range tests {
+		repo := &config.Repository{
+			Name:           tt.scheme,
+			Type:           "static",
+			URL:            tt.tags,
+			CurrentVersion: tt.current,
+			UpdatePolicy:   tt.policy,
+			Versioning:     &config.Versioning{Scheme: tt.scheme, IgnorePrefix: "v"},
+		}
+		result, err := NewScanner(false).Scan(context.Background(), repo)
+		if err != nil {
+			t.Errorf("%s: %v", tt.scheme, err)
+			continue
+		}
+		if result.LatestInRange != tt.want {
+			t.Errorf("%s: LatestInRange = %q, want %q", tt.scheme, result.LatestInRange, tt.want)
+		}
+	}
+}
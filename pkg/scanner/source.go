@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/wellcom-rocks/updates-sucks/pkg/config"
+)
+
+// Source lists the candidate versions published for a repository. The
+// returned versions are raw tag or release names; prefix handling, scheme
+// validation and sorting are applied afterwards by the Scanner. Sources must
+// stop and return ctx.Err() (possibly wrapped) once ctx is done.
+type Source interface {
+	ListVersions(ctx context.Context, repo *config.Repository) ([]string, error)
+}
+
+// SourceFactory creates a Source for a repository type.
+type SourceFactory func(verbose bool) Source
+
+var sources = map[string]SourceFactory{}
+
+// RegisterSource makes a Source available for the given repository type.
+// It is intended to be called from init functions and panics if the type is
+// already registered.
+func RegisterSource(repoType string, factory SourceFactory) {
+	if _, exists := sources[repoType]; exists {
+		panic(fmt.Sprintf("scanner: source for repository type %q already registered", repoType))
+	}
+	sources[repoType] = factory
+}
+
+// SourceTypes returns the registered repository types in sorted order.
+func SourceTypes() []string {
+	var types []string
+	for repoType := range sources {
+		types = append(types, repoType)
+	}
+	sort.Strings(types)
+	return types
+}
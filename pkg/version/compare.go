@@ -25,21 +25,33 @@ const (
 	Less
 )
 
+// semverRegex matches a SemVer 2.0.0 version, whose numeric components and
+// numeric pre-release identifiers have no leading zeros.
+var semverRegex = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*)(?:\.(?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*))*))?` +
+	`(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
 func ParseSemVer(version string) (*Version, error) {
 	// Remove leading 'v' if present
 	v := strings.TrimPrefix(version, "v")
 	
-	// Regex for semantic versioning
-	semverRegex := regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
-	
 	matches := semverRegex.FindStringSubmatch(v)
 	if matches == nil {
 		return nil, fmt.Errorf("invalid semver format: %s", version)
 	}
 	
-	major, _ := strconv.Atoi(matches[1])
-	minor, _ := strconv.Atoi(matches[2])
-	patch, _ := strconv.Atoi(matches[3])
+	major, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid semver format: %s: %w", version, err)
+	}
+	minor, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid semver format: %s: %w", version, err)
+	}
+	patch, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid semver format: %s: %w", version, err)
+	}
 	
 	return &Version{
 		Original:   version,
@@ -65,6 +77,21 @@ func CompareSemVer(current, latest string) (CompareResult, error) {
 	return currentVer.Compare(latestVer), nil
 }
 
+// String returns the version in canonical form, without a "v" prefix,
+// e.g. "1.2.3-rc.1+build.5". Parsing it yields an equal version.
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare orders versions by SemVer 2.0.0 precedence. Build metadata does
+// not take part, so versions differing only in it are Equal.
 func (v *Version) Compare(other *Version) CompareResult {
 	// Compare major version
 	if v.Major > other.Major {
@@ -92,16 +119,54 @@ func (v *Version) Compare(other *Version) CompareResult {
 		return Greater // Release version is greater than pre-release
 	} else if v.PreRelease != "" && other.PreRelease == "" {
 		return Less // Pre-release is less than release
-	} else if v.PreRelease != "" && other.PreRelease != "" {
-		// Both have pre-release, compare lexicographically
-		if v.PreRelease > other.PreRelease {
-			return Greater
-		} else if v.PreRelease < other.PreRelease {
-			return Less
-		}
 	}
 	
-	return Equal
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// comparePreRelease compares the dot-separated identifiers of two
+// pre-releases from left to right: numeric identifiers numerically,
+// alphanumeric ones in ASCII order, and numeric ones lower than
+// alphanumeric ones. A pre-release whose identifiers are a prefix of the
+// other's has lower precedence, e.g. 1.0.0-alpha < 1.0.0-alpha.1.
+func comparePreRelease(a, b string) CompareResult {
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aID, bID := aIDs[i], bIDs[i]
+		aNumeric, bNumeric := isDigits(aID), isDigits(bID)
+
+		var result CompareResult
+		switch {
+		case aNumeric && bNumeric:
+			// Without leading zeros, the longer number is the larger one
+			result = compareInts(len(aID), len(bID))
+			if result == Equal {
+				result = compareInts(strings.Compare(aID, bID), 0)
+			}
+		case aNumeric:
+			result = Less
+		case bNumeric:
+			result = Greater
+		default:
+			result = compareInts(strings.Compare(aID, bID), 0)
+		}
+		if result != Equal {
+			return result
+		}
+	}
+
+	return compareInts(len(aIDs), len(bIDs))
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return s != ""
 }
 
 func CompareCalVer(current, latest string) (CompareResult, error) {
@@ -172,7 +237,7 @@ func SortSemVer(tags []string) []string {
 		}
 	}
 	
-	sort.Slice(versions, func(i, j int) bool {
+	sort.SliceStable(versions, func(i, j int) bool {
 		return versions[i].Compare(versions[j]) == Less
 	})
 	
@@ -208,6 +273,46 @@ func GetLatestVersion(tags []string, scheme string) (string, error) {
 		sorted := SortCalVer(validTags)
 		return sorted[len(sorted)-1], nil
 		
+	case "pep440":
+		validTags := FilterValidPEP440(tags)
+		if len(validTags) == 0 {
+			return "", fmt.Errorf("no valid PEP 440 versions found")
+		}
+		sorted := SortPEP440(validTags)
+		return sorted[len(sorted)-1], nil
+
+	case "maven":
+		validTags := FilterValidMaven(tags)
+		if len(validTags) == 0 {
+			return "", fmt.Errorf("no valid maven versions found")
+		}
+		sorted := SortMaven(validTags)
+		return sorted[len(sorted)-1], nil
+
+	case "debian":
+		validTags := FilterValidDebian(tags)
+		if len(validTags) == 0 {
+			return "", fmt.Errorf("no valid debian versions found")
+		}
+		sorted := SortDebian(validTags)
+		return sorted[len(sorted)-1], nil
+
+	case "rpm":
+		validTags := FilterValidRPM(tags)
+		if len(validTags) == 0 {
+			return "", fmt.Errorf("no valid rpm versions found")
+		}
+		sorted := SortRPM(validTags)
+		return sorted[len(sorted)-1], nil
+
+	case "loose":
+		validTags := FilterValidLoose(tags)
+		if len(validTags) == 0 {
+			return "", fmt.Errorf("no valid versions found")
+		}
+		sorted := SortLoose(validTags)
+		return sorted[len(sorted)-1], nil
+
 	case "string":
 		if len(tags) == 0 {
 			return "", fmt.Errorf("no tags found")
@@ -218,4 +323,28 @@ func GetLatestVersion(tags []string, scheme string) (string, error) {
 	default:
 		return "", fmt.Errorf("unsupported versioning scheme: %s", scheme)
 	}
-}
\ No newline at end of file
+}
+// Compare compares two versions of scheme, returning Less if current is
+// older than latest.
+func Compare(current, latest, scheme string) (CompareResult, error) {
+	switch scheme {
+	case "semver":
+		return CompareSemVer(current, latest)
+	case "calver":
+		return CompareCalVer(current, latest)
+	case "pep440":
+		return ComparePEP440(current, latest)
+	case "maven":
+		return CompareMaven(current, latest)
+	case "debian":
+		return CompareDebian(current, latest)
+	case "rpm":
+		return CompareRPM(current, latest)
+	case "loose":
+		return CompareLoose(current, latest)
+	case "string":
+		return CompareString(current, latest)
+	default:
+		return Equal, fmt.Errorf("unsupported versioning scheme: %s", scheme)
+	}
+}
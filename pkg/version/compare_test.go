@@ -0,0 +1,142 @@
+package version
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// precedenceChain is the example of SemVer 2.0.0 section 11, in ascending
+// order, extended by numeric identifiers that must not compare as strings.
+var precedenceChain = []string{
+	"1.0.0-alpha",
+	"1.0.0-alpha.1",
+	"1.0.0-alpha.beta",
+	"1.0.0-beta",
+	"1.0.0-beta.2",
+	"1.0.0-beta.11",
+	"1.0.0-rc.1",
+	"1.0.0-rc.2",
+	"1.0.0-rc.10",
+	"1.0.0",
+	"1.0.1",
+	"1.1.0",
+	"1.10.0",
+	"2.0.0",
+	"10.0.0",
+}
+
+func TestComparePrecedenceChain(t *testing.T) {
+	testAscending(t, CompareSemVer, precedenceChain)
+}
+
+func TestSortSemVer(t *testing.T) {
+	shuffled := slices.Clone(precedenceChain)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	if got := SortSemVer(shuffled); !slices.Equal(got, precedenceChain) {
+		t.Errorf("SortSemVer() = %v, want %v", got, precedenceChain)
+	}
+}
+
+func TestCompareSemVer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want CompareResult
+	}{
+		{"1.0.0-rc.2", "1.0.0-rc.10", Less},
+		{"1.0.0-alpha", "1.0.0-alpha.0", Less},
+		// Numeric identifiers have lower precedence than alphanumeric ones
+		{"1.0.0-1", "1.0.0-alpha", Less},
+		{"1.0.0-alpha.9", "1.0.0-alpha.a", Less},
+		// Alphanumeric identifiers compare in ASCII order
+		{"1.0.0-Beta", "1.0.0-alpha", Less},
+		{"1.0.0-alpha-2", "1.0.0-alpha-10", Greater},
+		// Build metadata is ignored
+		{"1.0.0+build.1", "1.0.0+build.2", Equal},
+		{"1.0.0-alpha+001", "1.0.0-alpha", Equal},
+		{"1.0.0+20130313144700", "1.0.0-beta+exp.sha.5114f85", Greater},
+		{"v1.2.3", "1.2.3", Equal},
+	}
+
+	for _, tt := range tests {
+		got, err := CompareSemVer(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("CompareSemVer(%q, %q): %v", tt.a, tt.b, err)
+		}
+		if got != tt.want {
+			t.Errorf("CompareSemVer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseSemVerInvalid(t *testing.T) {
+	for _, v := range []string{
+		"01.0.0",
+		"1.01.0",
+		"1.0.01",
+		"1.0.0-01",
+		"1.0.0-alpha.01",
+		"1.0",
+		"1.0.0-",
+		"1.0.0+",
+		"1.0.0-alpha..1",
+		"1.0.0+build..1",
+		"1.0.0-al_pha",
+		"",
+	} {
+		if parsed, err := ParseSemVer(v); err == nil {
+			t.Errorf("ParseSemVer(%q) = %+v, want error", v, parsed)
+		}
+	}
+}
+
+func TestParseSemVer(t *testing.T) {
+	v, err := ParseSemVer("v1.0.0-0A.is.legal+build.1-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Version{
+		Original:   "v1.0.0-0A.is.legal+build.1-x",
+		Major:      1,
+		PreRelease: "0A.is.legal",
+		Build:      "build.1-x",
+	}
+	if *v != want {
+		t.Errorf("ParseSemVer() = %+v, want %+v", *v, want)
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3"},
+		{"1.0.0-rc.1", "1.0.0-rc.1"},
+		{"1.0.0+build.5", "1.0.0+build.5"},
+		{"v10.20.30-alpha.beta.1+exp.sha.5114f85", "10.20.30-alpha.beta.1+exp.sha.5114f85"},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseSemVer(tt.version)
+		if err != nil {
+			t.Fatalf("ParseSemVer(%q): %v", tt.version, err)
+		}
+		if got := v.String(); got != tt.want {
+			t.Errorf("ParseSemVer(%q).String() = %q, want %q", tt.version, got, tt.want)
+		}
+
+		// The canonical form parses to the same version
+		reparsed, err := ParseSemVer(v.String())
+		if err != nil {
+			t.Fatalf("ParseSemVer(%q): %v", v.String(), err)
+		}
+		if reparsed.String() != v.String() || reparsed.Build != v.Build || reparsed.Compare(v) != Equal {
+			t.Errorf("ParseSemVer(%q) = %+v, want %+v", v.String(), reparsed, v)
+		}
+	}
+}
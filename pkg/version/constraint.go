@@ -0,0 +1,276 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a semver range in the syntax of npm and Cargo, such as
+// "^2.0", "~1.28", ">=1.2 <2", "1.x || >=2.5.0" or "1.2 - 1.4". A version
+// satisfies it if it satisfies all comparators of one of the ranges
+// separated by "||".
+//
+// Pre-releases are opt-in: a pre-release such as 1.3.0-beta.1 only
+// satisfies a range with a comparator on a pre-release of the same
+// major.minor.patch, e.g. ">=1.3.0-alpha", so that "^1.2" does not select
+// the pre-releases of every future 1.x release.
+type Constraint struct {
+	Original string
+	sets     [][]comparator
+}
+
+// comparator compares a version against a bound with op, one of "=", "<",
+// "<=", ">" and ">=".
+type comparator struct {
+	op      string
+	version *Version
+}
+
+// partialVersion is a version in a constraint, in which minor and patch
+// may be omitted or be the wildcards "x", "X" or "*", e.g. the "1.2" of
+// "~1.2" or the "1.x" of "1.x".
+type partialVersion struct {
+	major, minor, patch int
+	// parts is the number of components given before the first wildcard:
+	// 0 for "*", 1 for "1" or "1.x", 2 for "1.2" and 3 for "1.2.3"
+	parts      int
+	preRelease string
+}
+
+var partialVersionRegex = regexp.MustCompile(`^v?(0|[1-9]\d*|[xX*])(?:\.(0|[1-9]\d*|[xX*])(?:\.(0|[1-9]\d*|[xX*])` +
+	`(?:-((?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*)(?:\.(?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*))*))?)?)?(?:\+[0-9A-Za-z-.]+)?$`)
+
+func ParseConstraint(expr string) (*Constraint, error) {
+	c := &Constraint{Original: expr}
+
+	for _, alternative := range strings.Split(expr, "||") {
+		set, err := parseComparatorSet(alternative)
+		if err != nil {
+			return nil, fmt.Errorf("invalid constraint %q: %w", expr, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+// parseComparatorSet parses a range without "||", whose comparators are
+// separated by whitespace.
+func parseComparatorSet(s string) ([]comparator, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	var set []comparator
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+
+		// Hyphen range "1.2.3 - 2.3.4" := >=1.2.3 <=2.3.4
+		if i+2 < len(fields) && fields[i+1] == "-" {
+			lower, err := parsePartialVersion(field)
+			if err != nil {
+				return nil, err
+			}
+			upper, err := parsePartialVersion(fields[i+2])
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, lower.comparators(">=")...)
+			set = append(set, upper.comparators("<=")...)
+			i += 2
+			continue
+		}
+
+		op := ""
+		for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(field, prefix) {
+				op = prefix
+				break
+			}
+		}
+		// Allow a space between operator and version, as in ">= 1.2"
+		if op != "" && field == op && i+1 < len(fields) {
+			i++
+			field += fields[i]
+		}
+
+		v, err := parsePartialVersion(strings.TrimPrefix(field, op))
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, v.comparators(op)...)
+	}
+
+	return set, nil
+}
+
+func parsePartialVersion(s string) (*partialVersion, error) {
+	matches := partialVersionRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version: %s", s)
+	}
+
+	v := &partialVersion{}
+	for _, part := range []*int{&v.major, &v.minor, &v.patch} {
+		n, err := strconv.Atoi(matches[v.parts+1])
+		if err != nil {
+			// Missing or a wildcard, which also covers the components after it
+			break
+		}
+		*part = n
+		v.parts++
+	}
+	if v.parts == 3 {
+		v.preRelease = matches[4]
+	}
+	return v, nil
+}
+
+func (v *partialVersion) version() *Version {
+	return &Version{Major: v.major, Minor: v.minor, Patch: v.patch, PreRelease: v.preRelease}
+}
+
+// next returns the lowest version above all versions matching v, e.g.
+// 1.3.0 for "1.2" and 2.0.0 for "1".
+func (v *partialVersion) next() *Version {
+	switch v.parts {
+	case 1:
+		return &Version{Major: v.major + 1}
+	case 2:
+		return &Version{Major: v.major, Minor: v.minor + 1}
+	default:
+		return &Version{Major: v.major, Minor: v.minor, Patch: v.patch + 1}
+	}
+}
+
+// comparators desugars an operator applied to v into plain comparisons.
+func (v *partialVersion) comparators(op string) []comparator {
+	if v.parts == 0 {
+		switch op {
+		case "<", ">":
+			// Nothing is below or above every version
+			return []comparator{{"<", &Version{}}}
+		default:
+			return nil
+		}
+	}
+
+	switch op {
+	case "^":
+		// Changes that do not modify the left-most non-zero component
+		upper := &Version{Major: v.major + 1}
+		switch {
+		case v.major == 0 && v.parts == 1:
+			// ^0 := <1.0.0
+		case v.major == 0 && (v.minor > 0 || v.parts == 2):
+			upper = &Version{Minor: v.minor + 1}
+		case v.major == 0:
+			upper = &Version{Patch: v.patch + 1}
+		}
+		return []comparator{{">=", v.version()}, {"<", upper}}
+
+	case "~":
+		// Patch changes if minor is given, minor changes otherwise
+		upper := &Version{Major: v.major, Minor: v.minor + 1}
+		if v.parts == 1 {
+			upper = &Version{Major: v.major + 1}
+		}
+		return []comparator{{">=", v.version()}, {"<", upper}}
+
+	case ">":
+		if v.parts < 3 {
+			return []comparator{{">=", v.next()}}
+		}
+		return []comparator{{">", v.version()}}
+
+	case "<=":
+		if v.parts < 3 {
+			return []comparator{{"<", v.next()}}
+		}
+		return []comparator{{"<=", v.version()}}
+
+	case ">=", "<":
+		return []comparator{{op, v.version()}}
+
+	default:
+		if v.parts < 3 {
+			return []comparator{{">=", v.version()}, {"<", v.next()}}
+		}
+		return []comparator{{"=", v.version()}}
+	}
+}
+
+func (c comparator) check(v *Version) bool {
+	result := v.Compare(c.version)
+	switch c.op {
+	case "<":
+		return result == Less
+	case "<=":
+		return result != Greater
+	case ">":
+		return result == Greater
+	case ">=":
+		return result != Less
+	default:
+		return result == Equal
+	}
+}
+
+// satisfies reports whether v satisfies all comparators of set, a
+// pre-release only doing so if one of them opts into the pre-releases of
+// its major.minor.patch.
+func satisfies(set []comparator, v *Version) bool {
+	for _, comp := range set {
+		if !comp.check(v) {
+			return false
+		}
+	}
+	if v.PreRelease == "" {
+		return true
+	}
+
+	for _, comp := range set {
+		bound := comp.version
+		if bound.PreRelease != "" && bound.Major == v.Major && bound.Minor == v.Minor && bound.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports whether v satisfies the constraint.
+func (c *Constraint) Check(v *Version) bool {
+	for _, set := range c.sets {
+		if satisfies(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSatisfying returns the highest of versions satisfying the constraint,
+// or "" if none does. Versions that are not valid semver are ignored.
+func (c *Constraint) MaxSatisfying(versions []string) string {
+	var max *Version
+	for _, s := range versions {
+		v, err := ParseSemVer(s)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if max == nil || v.Compare(max) == Greater {
+			max = v
+		}
+	}
+
+	if max == nil {
+		return ""
+	}
+	return max.Original
+}
+
+func (c *Constraint) String() string {
+	return c.Original
+}
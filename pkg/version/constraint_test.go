@@ -0,0 +1,139 @@
+package version
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		// Unions
+		{"1.x || >=2.5.0", "1.9.9", true},
+		{"1.x || >=2.5.0", "2.4.0", false},
+		{"1.x || >=2.5.0", "2.5.0", true},
+		{"^1.2 || ^3", "2.0.0", false},
+		{"^1.2 || ^3", "3.1.0", true},
+
+		// Hyphen ranges, where a partial upper bound includes all its versions
+		{"1.2.3 - 2.3.4", "1.2.3", true},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		{"1.2.3 - 2.3.4", "1.2.2", false},
+		{"1.2 - 2.3", "1.2.0", true},
+		{"1.2 - 2.3", "1.1.9", false},
+		{"1.2 - 2.3", "2.3.9", true},
+		{"1.2 - 2.3", "2.4.0", false},
+		{"1.2 - 2.3", "2.4.0-0", false},
+		{"1.2.3 - 2", "2.9.9", true},
+		{"1.2.3 - 2", "3.0.0", false},
+
+		// Wildcards
+		{"1.x", "1.0.0", true},
+		{"1.x", "2.0.0", false},
+		{"1.X", "1.5.0", true},
+		{"1.2.*", "1.2.9", true},
+		{"1.2.*", "1.3.0", false},
+		{"1", "1.9.0", true},
+		{"*", "9.9.9", true},
+		{"*", "1.0.0-rc.1", false},
+		{">1.x", "2.0.0", true},
+		{">1.x", "1.9.9", false},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"<*", "0.0.0", false},
+
+		// ~ and ^ below 1.0.0
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.2", "0.2.9", true},
+		{"^0.2", "0.3.0", false},
+		{"^0.0", "0.0.9", true},
+		{"^0.0", "0.1.0", false},
+		{"^0", "0.9.9", true},
+		{"^0", "1.0.0", false},
+		{"~0.2.3", "0.2.9", true},
+		{"~0.2.3", "0.3.0", false},
+		{"~0", "0.9.0", true},
+		{"~0", "1.0.0", false},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+
+		// Pre-releases only satisfy ranges opting into their major.minor.patch
+		{">=1.2.3-beta.1", "1.2.3-beta.2", true},
+		{">=1.2.3-beta.1", "1.2.3-alpha", false},
+		{">=1.2.3-beta.1", "1.2.4-beta", false},
+		{">=1.2.3-beta.1", "1.2.4", true},
+		{"^1.2.3-beta.1", "1.2.3-beta.5", true},
+		{"^1.2.3-beta.1", "1.3.0-beta", false},
+		{"^1.2", "1.3.0-rc.1", false},
+
+		// Operators, with or without a space before the version
+		{">= 1.2 <2", "1.5.0", true},
+		{">= 1.2 < 2", "2.0.0", false},
+		{"=1.2.3", "1.2.3", true},
+		{"v1.2.3", "1.2.4", false},
+		{">1.2.3 <=1.2.5", "1.2.5", true},
+		{">1.2.3 <=1.2.5", "1.2.3", false},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.constraint, err)
+			continue
+		}
+		v, err := ParseSemVer(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Check(v); got != tt.want {
+			t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintMaxSatisfying(t *testing.T) {
+	versions := []string{"1.2.0", "2.0.0", "1.10.0", "1.9.5", "v1.11.0-rc.1", "garbage", "1.3.0"}
+
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"^1.2", "1.10.0"},
+		{"~1.9", "1.9.5"},
+		{"~1.11.0-rc.0", "v1.11.0-rc.1"},
+		{"1.2 - 1.3 || 2", "2.0.0"},
+		{"<1.0", ""},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tt.constraint, err)
+		}
+		if got := c.MaxSatisfying(versions); got != tt.want {
+			t.Errorf("%q.MaxSatisfying() = %q, want %q", tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{
+		"",
+		"||",
+		"1.2.3 ||",
+		">=",
+		">=abc",
+		"1.2.3.4",
+		"01.2.3",
+		"^1.2.3-",
+		"~>1.2",
+		"1.2.3 -",
+	} {
+		if _, err := ParseConstraint(constraint); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want error", constraint)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DebianVersion is a Debian package version of the form
+// [epoch:]upstream_version[-debian_revision], e.g. "1:2.36.1-8+deb12u1".
+type DebianVersion struct {
+	Original string
+	Epoch    int
+	Upstream string
+	Revision string
+}
+
+var (
+	debianUpstreamRegex = regexp.MustCompile(`^[0-9][A-Za-z0-9.+~:-]*$`)
+	debianRevisionRegex = regexp.MustCompile(`^[A-Za-z0-9.+~]+$`)
+)
+
+func ParseDebianVersion(version string) (*DebianVersion, error) {
+	v := &DebianVersion{Original: version}
+	rest := strings.TrimSpace(version)
+
+	if epoch, upstream, found := strings.Cut(rest, ":"); found {
+		n, err := strconv.Atoi(epoch)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid debian version epoch: %s", version)
+		}
+		v.Epoch = n
+		rest = upstream
+	}
+
+	if i := strings.LastIndex(rest, "-"); i >= 0 {
+		v.Revision = rest[i+1:]
+		rest = rest[:i]
+		if !debianRevisionRegex.MatchString(v.Revision) {
+			return nil, fmt.Errorf("invalid debian version revision: %s", version)
+		}
+	}
+
+	if !debianUpstreamRegex.MatchString(rest) {
+		return nil, fmt.Errorf("invalid debian version: %s", version)
+	}
+	v.Upstream = rest
+
+	return v, nil
+}
+
+func CompareDebian(current, latest string) (CompareResult, error) {
+	currentVer, err := ParseDebianVersion(current)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse current version: %w", err)
+	}
+
+	latestVer, err := ParseDebianVersion(latest)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse latest version: %w", err)
+	}
+
+	return currentVer.Compare(latestVer), nil
+}
+
+// Compare orders versions like dpkg --compare-versions: by epoch, then
+// upstream version, then revision.
+func (v *DebianVersion) Compare(other *DebianVersion) CompareResult {
+	if result := compareInts(v.Epoch, other.Epoch); result != Equal {
+		return result
+	}
+	if result := compareInts(dpkgVerRevCmp(v.Upstream, other.Upstream), 0); result != Equal {
+		return result
+	}
+	return compareInts(dpkgVerRevCmp(v.Revision, other.Revision), 0)
+}
+
+// dpkgOrder is the sort weight of a character in a non-digit part: '~'
+// sorts before everything, even the end of the part, and letters sort
+// before other characters.
+func dpkgOrder(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return 0
+	case (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+		return int(c)
+	case c == '~':
+		return -1
+	case c != 0:
+		return int(c) + 256
+	default:
+		return 0
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// dpkgVerRevCmp is dpkg's verrevcmp: it alternately compares non-digit
+// parts character by character and digit parts numerically.
+func dpkgVerRevCmp(a, b string) int {
+	at := func(s string, i int) byte {
+		if i < len(s) {
+			return s[i]
+		}
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		firstDiff := 0
+
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac := dpkgOrder(at(a, i))
+			bc := dpkgOrder(at(b, j))
+			if ac != bc {
+				return ac - bc
+			}
+			i++
+			j++
+		}
+
+		for at(a, i) == '0' {
+			i++
+		}
+		for at(b, j) == '0' {
+			j++
+		}
+
+		for isDigit(at(a, i)) && isDigit(at(b, j)) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+
+		if isDigit(at(a, i)) {
+			return 1
+		}
+		if isDigit(at(b, j)) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+
+	return 0
+}
+
+func FilterValidDebian(tags []string) []string {
+	var validTags []string
+	for _, tag := range tags {
+		if _, err := ParseDebianVersion(tag); err == nil {
+			validTags = append(validTags, tag)
+		}
+	}
+	return validTags
+}
+
+func SortDebian(tags []string) []string {
+	var versions []*DebianVersion
+	for _, tag := range tags {
+		if v, err := ParseDebianVersion(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) == Less
+	})
+
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.Original)
+	}
+
+	return sorted
+}
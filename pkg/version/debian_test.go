@@ -0,0 +1,39 @@
+package version
+
+import "testing"
+
+func TestCompareDebian(t *testing.T) {
+	testAscending(t, CompareDebian, []string{
+		"1.0~~",
+		"1.0~rc1",
+		"1.0",
+		"1.0-1",
+		"1.0-1+deb12u1",
+		"1.0-2",
+		"1.0-10",
+		"1.0a",
+		"1.0+dfsg",
+		"1.0.1",
+		"2.36.1",
+		"2.36.1-8",
+		"1:0.9",
+		"1:2.40-1",
+	})
+}
+
+func TestCompareDebianEquivalent(t *testing.T) {
+	tests := [][2]string{
+		{"0:1.0", "1.0"},
+		{"1.0-0", "1.0"},
+		{"1.01", "1.1"},
+	}
+	for _, tt := range tests {
+		got, err := CompareDebian(tt[0], tt[1])
+		if err != nil {
+			t.Fatalf("CompareDebian(%q, %q): %v", tt[0], tt[1], err)
+		}
+		if got != Equal {
+			t.Errorf("CompareDebian(%q, %q) = %v, want Equal", tt[0], tt[1], got)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LooseVersion is a version tag as found in the wild, normalised to semver:
+// a textual prefix such as "release-" is dropped, missing minor and patch
+// components are zero ("v1.28" is 1.28.0), components after the patch are
+// kept in Extra ("1.2.3.4") and a qualifier after any of "-", "_" or "."
+// becomes the pre-release ("1.2.3_beta1" is 1.2.3-beta1), except for
+// qualifiers marking a release such as "2.0.0.Final". Tags without dots may
+// separate their components with "-" or "_" instead, so that the date in
+// "release/2024-05-01" is 2024.5.1 rather than the pre-release 2024.0.0-5.1.
+type LooseVersion struct {
+	Original string
+	Major    int
+	Minor    int
+	Patch    int
+	Extra    []int
+	// PreRelease holds dot-separated identifiers, e.g. "rc.1"
+	PreRelease string
+	Build      string
+}
+
+var looseVersionRegex = regexp.MustCompile(`^(?:[A-Za-z]+[-_/.]?)*(\d+)(?:\.(\d+))?(?:\.(\d+))?((?:\.\d+)*)` +
+	`(?:([-_.]?)([0-9A-Za-z]+(?:[-_.][0-9A-Za-z]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+// looseDashedNumbersRegex matches the remaining components of a version
+// separated by "-" or "_", such as the "05-01" of "2024-05-01".
+var looseDashedNumbersRegex = regexp.MustCompile(`^\d+(?:[-_]\d+)*$`)
+
+// looseReleaseQualifiers mark a release rather than a pre-release.
+var looseReleaseQualifiers = map[string]bool{
+	"final":   true,
+	"ga":      true,
+	"release": true,
+}
+
+func ParseLoose(version string) (*LooseVersion, error) {
+	matches := looseVersionRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version: %s", version)
+	}
+
+	v := &LooseVersion{Original: version, Build: matches[7]}
+
+	// Components are given in order, e.g. a patch only after a minor
+	components := []string{matches[1], matches[2], matches[3]}
+	components = append(components, strings.Split(matches[4], ".")...)
+	qualifier := matches[6]
+	if matches[2] == "" && (matches[5] == "-" || matches[5] == "_") && looseDashedNumbersRegex.MatchString(qualifier) {
+		components = append(components, strings.FieldsFunc(qualifier, func(r rune) bool {
+			return r == '-' || r == '_'
+		})...)
+		qualifier = ""
+	}
+	var numbers []int
+	for _, component := range components {
+		if component == "" {
+			continue
+		}
+		n, err := strconv.Atoi(component)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version: %s: %w", version, err)
+		}
+		numbers = append(numbers, n)
+	}
+	numbers = append(numbers, 0, 0)
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	if len(numbers) > 5 {
+		v.Extra = numbers[3 : len(numbers)-2]
+	}
+
+	if !looseReleaseQualifiers[strings.ToLower(qualifier)] {
+		v.PreRelease = normalizeQualifier(qualifier)
+	}
+
+	return v, nil
+}
+
+// normalizeQualifier turns a qualifier into semver pre-release identifiers
+// as written, so that it orders like the same strict semver pre-release:
+// "_" separates identifiers like ".", and numeric identifiers lose their
+// leading zeros.
+func normalizeQualifier(qualifier string) string {
+	if qualifier == "" {
+		return ""
+	}
+	ids := strings.Split(strings.ReplaceAll(qualifier, "_", "."), ".")
+	for i, id := range ids {
+		if isDigits(id) {
+			if ids[i] = strings.TrimLeft(id, "0"); ids[i] == "" {
+				ids[i] = "0"
+			}
+		}
+	}
+	return strings.Join(ids, ".")
+}
+
+// String returns the normalised version, e.g. "1.2.3-beta1" for
+// "release-1.2.3_beta1".
+func (v *LooseVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	for _, n := range v.Extra {
+		s += "." + strconv.Itoa(n)
+	}
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Normalized reports whether the version had to be normalised, that is
+// whether it differs from its normal form other than by a "v" prefix.
+func (v *LooseVersion) Normalized() bool {
+	return strings.TrimPrefix(v.Original, "v") != v.String()
+}
+
+func CompareLoose(current, latest string) (CompareResult, error) {
+	currentVer, err := ParseLoose(current)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse current version: %w", err)
+	}
+
+	latestVer, err := ParseLoose(latest)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse latest version: %w", err)
+	}
+
+	return currentVer.Compare(latestVer), nil
+}
+
+// Compare orders normalised versions like semver, the Extra components
+// sorting between the patch and the pre-release, so that
+// 1.2.3 = 1.2.3.0 < 1.2.3.4 < 1.2.4-rc.1 < 1.2.4.
+func (v *LooseVersion) Compare(other *LooseVersion) CompareResult {
+	if result := compareReleases(v.release(), other.release()); result != Equal {
+		return result
+	}
+
+	switch {
+	case v.PreRelease == other.PreRelease:
+		return Equal
+	case v.PreRelease == "":
+		return Greater // Release version is greater than pre-release
+	case other.PreRelease == "":
+		return Less // Pre-release is less than release
+	default:
+		return comparePreRelease(v.PreRelease, other.PreRelease)
+	}
+}
+
+// release returns the numeric components of the version.
+func (v *LooseVersion) release() []int {
+	return append([]int{v.Major, v.Minor, v.Patch}, v.Extra...)
+}
+
+func FilterValidLoose(tags []string) []string {
+	var validTags []string
+	for _, tag := range tags {
+		if _, err := ParseLoose(tag); err == nil {
+			validTags = append(validTags, tag)
+		}
+	}
+	return validTags
+}
+
+func SortLoose(tags []string) []string {
+	var versions []*LooseVersion
+	for _, tag := range tags {
+		if v, err := ParseLoose(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) == Less
+	})
+
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.Original)
+	}
+
+	return sorted
+}
@@ -0,0 +1,94 @@
+package version
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseLoose(t *testing.T) {
+	tests := []struct {
+		version    string
+		want       string
+		normalized bool
+	}{
+		{"v1.28", "1.28.0", true},
+		{"1.2.3.4", "1.2.3.4", false},
+		{"release-1.2.3", "1.2.3", true},
+		{"1.2.3_beta1", "1.2.3-beta1", true},
+		{"1.2.3_beta_1", "1.2.3-beta.1", true},
+		{"2.0.0.Final", "2.0.0", true},
+		{"2.0.0.GA", "2.0.0", true},
+		{"v1.2.3", "1.2.3", false},
+		{"1.2.3-rc.1+build.5", "1.2.3-rc.1+build.5", false},
+		// Qualifiers keep their case and identifier boundaries
+		{"1.0.0-0A", "1.0.0-0A", false},
+		{"1.0.0-Beta", "1.0.0-Beta", false},
+		{"1.0.0-rc-1", "1.0.0-rc-1", false},
+		{"1.0.0.RC1", "1.0.0-RC1", true},
+		{"1.0.0rc1", "1.0.0-rc1", true},
+		{"1.0.0-rc.01", "1.0.0-rc.1", true},
+		// Components separated like a date in tags without dots
+		{"release/2024-05-01", "2024.5.1", true},
+		{"2024_05_01", "2024.5.1", true},
+		{"1.2.3-4", "1.2.3-4", false},
+		{"1-rc1", "1.0.0-rc1", true},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseLoose(tt.version)
+		if err != nil {
+			t.Errorf("ParseLoose(%q): %v", tt.version, err)
+			continue
+		}
+		if got := v.String(); got != tt.want {
+			t.Errorf("ParseLoose(%q) = %s, want %s", tt.version, got, tt.want)
+		}
+		if got := v.Normalized(); got != tt.normalized {
+			t.Errorf("ParseLoose(%q).Normalized() = %v, want %v", tt.version, got, tt.normalized)
+		}
+	}
+
+	for _, version := range []string{"", "latest", "1.2.3-", "1.2.3+", "1..2"} {
+		if _, err := ParseLoose(version); err == nil {
+			t.Errorf("ParseLoose(%q) succeeded, want error", version)
+		}
+	}
+}
+
+func TestCompareLoose(t *testing.T) {
+	testAscending(t, CompareLoose, []string{
+		"1.2.3-beta1",
+		"1.2.3",
+		"1.2.3.4",
+		"1.2.4-rc.1",
+		"v1.2.4",
+		"1.28",
+		"2.0.0.Final",
+		"release/2024-05-01",
+		"2024.5.2",
+	})
+}
+
+func TestCompareLooseAsSemVer(t *testing.T) {
+	// Strict semver tags order exactly as under semver, whatever the case
+	// of their pre-releases
+	chain := append(slices.Clone(precedenceChain[:9]), "1.0.0-0A", "1.0.0-Beta", "1.0.0-RC.1")
+	sorted := SortSemVer(chain)
+	testAscending(t, CompareLoose, sorted)
+}
+
+func TestSortLooseMixed(t *testing.T) {
+	tags := []string{"v1.28", "1.28.1", "release-1.29.0-rc.1", "1.28.0.1", "1.29.0_beta2", "1.29.0", "v1.27"}
+	want := []string{"v1.27", "v1.28", "1.28.0.1", "1.28.1", "1.29.0_beta2", "release-1.29.0-rc.1", "1.29.0"}
+	if got := SortLoose(tags); !slices.Equal(got, want) {
+		t.Errorf("SortLoose() = %v, want %v", got, want)
+	}
+
+	latest, err := GetLatestVersion(append(tags, "not-a-version"), "loose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "1.29.0" {
+		t.Errorf("GetLatestVersion() = %s, want 1.29.0", latest)
+	}
+}
@@ -0,0 +1,313 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MavenVersion is a version ordered like Maven's ComparableVersion, which
+// accepts any string and understands qualifiers such as "alpha", "M1",
+// "RC2", "SNAPSHOT", "Final" or "sp1".
+type MavenVersion struct {
+	Original string
+	items    *mavenList
+}
+
+// mavenItem is an element of a parsed Maven version: a number, a qualifier
+// or a nested list introduced by '-' or a digit/letter transition.
+type mavenItem interface {
+	// compare compares the item with other, which may be nil for a
+	// missing item, returning -1, 0 or 1.
+	compare(other mavenItem) int
+	isNull() bool
+}
+
+// mavenInt holds the digits of a number without leading zeros, so that
+// arbitrarily large numbers can be compared.
+type mavenInt string
+
+type mavenString string
+
+type mavenList []mavenItem
+
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+// mavenReleaseQualifier is the comparable form of the empty qualifier,
+// i.e. of a release without qualifier.
+var mavenReleaseQualifier = mavenComparableQualifier("")
+
+func ParseMavenVersion(version string) (*MavenVersion, error) {
+	if strings.TrimSpace(version) == "" {
+		return nil, fmt.Errorf("invalid maven version: %q", version)
+	}
+
+	v := strings.ToLower(version)
+	items := &mavenList{}
+	list := items
+	stack := []*mavenList{list}
+
+	startNewList := func() {
+		next := &mavenList{}
+		*list = append(*list, next)
+		list = next
+		stack = append(stack, list)
+	}
+
+	isDigit := false
+	start := 0
+	for i, c := range v {
+		switch {
+		case c == '.':
+			if i == start {
+				*list = append(*list, mavenInt(""))
+			} else {
+				*list = append(*list, parseMavenItem(isDigit, v[start:i]))
+			}
+			start = i + 1
+
+		case c == '-':
+			if i == start {
+				*list = append(*list, mavenInt(""))
+			} else {
+				*list = append(*list, parseMavenItem(isDigit, v[start:i]))
+			}
+			start = i + 1
+			startNewList()
+
+		case unicode.IsDigit(c):
+			if !isDigit && i > start {
+				*list = append(*list, newMavenString(v[start:i], true))
+				start = i
+				startNewList()
+			}
+			isDigit = true
+
+		default:
+			if isDigit && i > start {
+				*list = append(*list, parseMavenItem(true, v[start:i]))
+				start = i
+				startNewList()
+			}
+			isDigit = false
+		}
+	}
+
+	if len(v) > start {
+		*list = append(*list, parseMavenItem(isDigit, v[start:]))
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].normalize()
+	}
+
+	return &MavenVersion{Original: version, items: items}, nil
+}
+
+func parseMavenItem(isDigit bool, s string) mavenItem {
+	if isDigit {
+		return mavenInt(strings.TrimLeft(s, "0"))
+	}
+	return newMavenString(s, false)
+}
+
+func newMavenString(s string, followedByDigit bool) mavenString {
+	if followedByDigit && len(s) == 1 {
+		// a1 = alpha-1, b1 = beta-1, m1 = milestone-1
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[s]; ok {
+		s = alias
+	}
+	return mavenString(s)
+}
+
+// mavenComparableQualifier maps known qualifiers to their rank and sorts
+// unknown qualifiers after all known ones, alphabetically.
+func mavenComparableQualifier(qualifier string) string {
+	for i, q := range mavenQualifiers {
+		if q == qualifier {
+			return fmt.Sprint(i)
+		}
+	}
+	return fmt.Sprintf("%d-%s", len(mavenQualifiers), qualifier)
+}
+
+func (i mavenInt) isNull() bool {
+	return i == ""
+}
+
+func (i mavenInt) compare(other mavenItem) int {
+	switch other := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case mavenInt:
+		if len(i) != len(other) {
+			return compareOrdered(len(i), len(other))
+		}
+		return strings.Compare(string(i), string(other))
+	default:
+		// 1.1 > 1-sp and 1.1 > 1-1
+		return 1
+	}
+}
+
+func (s mavenString) isNull() bool {
+	return mavenComparableQualifier(string(s)) == mavenReleaseQualifier
+}
+
+func (s mavenString) compare(other mavenItem) int {
+	switch other := other.(type) {
+	case nil:
+		// 1-rc < 1, 1-sp > 1
+		return strings.Compare(mavenComparableQualifier(string(s)), mavenReleaseQualifier)
+	case mavenString:
+		return strings.Compare(mavenComparableQualifier(string(s)), mavenComparableQualifier(string(other)))
+	default:
+		// 1-alpha < 1.1 and 1-alpha < 1-1
+		return -1
+	}
+}
+
+func (l *mavenList) isNull() bool {
+	return len(*l) == 0
+}
+
+func (l *mavenList) compare(other mavenItem) int {
+	switch other := other.(type) {
+	case nil:
+		if len(*l) == 0 {
+			return 0
+		}
+		return (*l)[0].compare(nil)
+	case mavenInt:
+		// 1-1 < 1.0.x
+		return -1
+	case mavenString:
+		// 1-1 > 1-sp
+		return 1
+	case *mavenList:
+		for i := 0; i < len(*l) || i < len(*other); i++ {
+			var left, right mavenItem
+			if i < len(*l) {
+				left = (*l)[i]
+			}
+			if i < len(*other) {
+				right = (*other)[i]
+			}
+
+			var result int
+			if left == nil {
+				if right != nil {
+					result = -right.compare(nil)
+				}
+			} else {
+				result = left.compare(right)
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+// normalize removes trailing null items (0, "", empty lists), stopping at
+// the first non-null item that is not a list.
+func (l *mavenList) normalize() {
+	for i := len(*l) - 1; i >= 0; i-- {
+		item := (*l)[i]
+		if item.isNull() {
+			*l = append((*l)[:i], (*l)[i+1:]...)
+		} else if _, isList := item.(*mavenList); !isList {
+			break
+		}
+	}
+}
+
+func compareOrdered(a, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func (v *MavenVersion) Compare(other *MavenVersion) CompareResult {
+	switch v.items.compare(other.items) {
+	case 1:
+		return Greater
+	case -1:
+		return Less
+	default:
+		return Equal
+	}
+}
+
+func CompareMaven(current, latest string) (CompareResult, error) {
+	currentVer, err := ParseMavenVersion(current)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse current version: %w", err)
+	}
+
+	latestVer, err := ParseMavenVersion(latest)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse latest version: %w", err)
+	}
+
+	return currentVer.Compare(latestVer), nil
+}
+
+// FilterValidMaven keeps tags that start with a digit. Maven itself
+// accepts any string as version, but tags such as "latest" are not
+// versions.
+func FilterValidMaven(tags []string) []string {
+	var validTags []string
+	for _, tag := range tags {
+		if tag != "" && tag[0] >= '0' && tag[0] <= '9' {
+			validTags = append(validTags, tag)
+		}
+	}
+	return validTags
+}
+
+func SortMaven(tags []string) []string {
+	var versions []*MavenVersion
+	for _, tag := range tags {
+		if v, err := ParseMavenVersion(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) == Less
+	})
+
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.Original)
+	}
+
+	return sorted
+}
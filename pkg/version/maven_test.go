@@ -0,0 +1,82 @@
+package version
+
+import "testing"
+
+func TestCompareMaven(t *testing.T) {
+	// The qualifier and number orderings of Maven's ComparableVersionTest
+	testAscending(t, CompareMaven, []string{
+		"1-alpha2snapshot",
+		"1-alpha2",
+		"1-alpha-123",
+		"1-beta-2",
+		"1-beta123",
+		"1-m2",
+		"1-m11",
+		"1-rc",
+		"1-cr2",
+		"1-rc123",
+		"1-SNAPSHOT",
+		"1",
+		"1-sp",
+		"1-sp2",
+		"1-sp123",
+		"1-abc",
+		"1-def",
+		"1-pom-1",
+		"1-1-snapshot",
+		"1-1",
+		"1-2",
+		"1-123",
+	})
+	testAscending(t, CompareMaven, []string{
+		"2.0",
+		"2-1",
+		"2.0.a",
+		"2.0.0.a",
+		"2.0.2",
+		"2.0.123",
+		"2.1.0",
+		"2.1-a",
+		"2.1b",
+		"2.1-c",
+		"2.1-1",
+		"2.1.0.1",
+		"2.2",
+		"2.123",
+		"11.a2",
+		"11.a11",
+		"11.b2",
+		"11.b11",
+		"11.m2",
+		"11.m11",
+		"11",
+		"11.a",
+		"11b",
+		"11c",
+		"11m",
+	})
+}
+
+func TestCompareMavenEquivalent(t *testing.T) {
+	tests := [][2]string{
+		{"1", "1.0.0"},
+		{"1-ga", "1"},
+		{"1.0.Final", "1"},
+		{"1-release", "1"},
+		{"1a1", "1-alpha-1"},
+		{"1b2", "1-beta-2"},
+		{"1m3", "1-milestone-3"},
+		{"1cr", "1rc"},
+		{"1X", "1x"},
+		{"1.0-SNAPSHOT", "1-snapshot"},
+	}
+	for _, tt := range tests {
+		got, err := CompareMaven(tt[0], tt[1])
+		if err != nil {
+			t.Fatalf("CompareMaven(%q, %q): %v", tt[0], tt[1], err)
+		}
+		if got != Equal {
+			t.Errorf("CompareMaven(%q, %q) = %v, want Equal", tt[0], tt[1], got)
+		}
+	}
+}
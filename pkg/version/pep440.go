@@ -0,0 +1,252 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PEP440Version is a Python package version as specified by PEP 440, e.g.
+// "1!2.0.0rc1.post2.dev3+local.7".
+type PEP440Version struct {
+	Original string
+	Epoch    int
+	Release  []int
+	// PreLabel is "a", "b" or "rc" (normalised from alpha, beta, c, pre
+	// and preview); empty if the version is not a pre-release.
+	PreLabel  string
+	PreNumber int
+	// Post and Dev are -1 if the version has no post or dev segment.
+	Post  int
+	Dev   int
+	Local string
+}
+
+var pep440Regex = regexp.MustCompile(`(?i)^v?` +
+	`(?:(\d+)!)?` + // epoch
+	`(\d+(?:\.\d+)*)` + // release
+	`(?:[-_.]?(alpha|a|beta|b|preview|pre|c|rc)[-_.]?(\d+)?)?` + // pre-release
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` + // post-release
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` + // dev release
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`) // local version
+
+func ParsePEP440(version string) (*PEP440Version, error) {
+	matches := pep440Regex.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return nil, fmt.Errorf("invalid PEP 440 version: %s", version)
+	}
+
+	v := &PEP440Version{
+		Original: version,
+		Post:     -1,
+		Dev:      -1,
+	}
+
+	if matches[1] != "" {
+		v.Epoch, _ = strconv.Atoi(matches[1])
+	}
+
+	for _, part := range strings.Split(matches[2], ".") {
+		n, _ := strconv.Atoi(part)
+		v.Release = append(v.Release, n)
+	}
+
+	if matches[3] != "" {
+		switch strings.ToLower(matches[3]) {
+		case "a", "alpha":
+			v.PreLabel = "a"
+		case "b", "beta":
+			v.PreLabel = "b"
+		default:
+			v.PreLabel = "rc"
+		}
+		v.PreNumber, _ = strconv.Atoi(matches[4])
+	}
+
+	if matches[5] != "" {
+		v.Post, _ = strconv.Atoi(matches[5])
+	} else if matches[6] != "" {
+		v.Post, _ = strconv.Atoi(matches[7])
+	}
+
+	if matches[8] != "" {
+		v.Dev, _ = strconv.Atoi(matches[9])
+	}
+
+	v.Local = strings.ToLower(matches[10])
+
+	return v, nil
+}
+
+func ComparePEP440(current, latest string) (CompareResult, error) {
+	currentVer, err := ParsePEP440(current)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse current version: %w", err)
+	}
+
+	latestVer, err := ParsePEP440(latest)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse latest version: %w", err)
+	}
+
+	return currentVer.Compare(latestVer), nil
+}
+
+// Compare orders versions as pip does: by epoch, release (ignoring trailing
+// zeros), then dev releases before pre-releases before the final release
+// before post-releases, and finally by local version.
+func (v *PEP440Version) Compare(other *PEP440Version) CompareResult {
+	if result := compareInts(v.Epoch, other.Epoch); result != Equal {
+		return result
+	}
+
+	if result := compareReleases(v.Release, other.Release); result != Equal {
+		return result
+	}
+
+	if result := compareInts(v.preRank(), other.preRank()); result != Equal {
+		return result
+	}
+	if v.PreLabel != "" {
+		if result := compareInts(v.PreNumber, other.PreNumber); result != Equal {
+			return result
+		}
+	}
+
+	// Versions without a post segment sort before any post-release
+	if result := compareInts(v.Post, other.Post); result != Equal {
+		return result
+	}
+
+	// Versions without a dev segment sort after any dev release
+	vDev, otherDev := v.Dev, other.Dev
+	if vDev < 0 {
+		vDev = int(^uint(0) >> 1)
+	}
+	if otherDev < 0 {
+		otherDev = int(^uint(0) >> 1)
+	}
+	if result := compareInts(vDev, otherDev); result != Equal {
+		return result
+	}
+
+	return compareLocal(v.Local, other.Local)
+}
+
+// preRank orders the pre-release phase: a dev release of the final version
+// ("1.0.dev1") comes before any pre-release, a final or post release after.
+func (v *PEP440Version) preRank() int {
+	switch v.PreLabel {
+	case "a":
+		return 1
+	case "b":
+		return 2
+	case "rc":
+		return 3
+	}
+	if v.Post < 0 && v.Dev >= 0 {
+		return 0
+	}
+	return 4
+}
+
+func compareInts(a, b int) CompareResult {
+	if a > b {
+		return Greater
+	} else if a < b {
+		return Less
+	}
+	return Equal
+}
+
+func compareReleases(a, b []int) CompareResult {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if result := compareInts(x, y); result != Equal {
+			return result
+		}
+	}
+	return Equal
+}
+
+// compareLocal compares local version labels segment by segment, numeric
+// segments sorting after alphanumeric ones.
+func compareLocal(a, b string) CompareResult {
+	if a == b {
+		return Equal
+	}
+	if a == "" {
+		return Less
+	}
+	if b == "" {
+		return Greater
+	}
+
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+	aParts, bParts := split(a), split(b)
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if result := compareInts(aNum, bNum); result != Equal {
+				return result
+			}
+		case aErr == nil:
+			return Greater
+		case bErr == nil:
+			return Less
+		default:
+			if aParts[i] > bParts[i] {
+				return Greater
+			} else if aParts[i] < bParts[i] {
+				return Less
+			}
+		}
+	}
+
+	return compareInts(len(aParts), len(bParts))
+}
+
+func FilterValidPEP440(tags []string) []string {
+	var validTags []string
+	for _, tag := range tags {
+		if _, err := ParsePEP440(tag); err == nil {
+			validTags = append(validTags, tag)
+		}
+	}
+	return validTags
+}
+
+func SortPEP440(tags []string) []string {
+	var versions []*PEP440Version
+	for _, tag := range tags {
+		if v, err := ParsePEP440(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) == Less
+	})
+
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.Original)
+	}
+
+	return sorted
+}
@@ -0,0 +1,83 @@
+package version
+
+import "testing"
+
+// testAscending checks that compare orders each version of chain, which
+// is in ascending order, before all later ones.
+func testAscending(t *testing.T, compare func(a, b string) (CompareResult, error), chain []string) {
+	t.Helper()
+	for i := range chain {
+		for j := range chain {
+			want := Equal
+			switch {
+			case i < j:
+				want = Less
+			case i > j:
+				want = Greater
+			}
+			got, err := compare(chain[i], chain[j])
+			if err != nil {
+				t.Fatalf("compare(%q, %q): %v", chain[i], chain[j], err)
+			}
+			if got != want {
+				t.Errorf("compare(%q, %q) = %v, want %v", chain[i], chain[j], got, want)
+			}
+		}
+	}
+}
+
+func TestComparePEP440(t *testing.T) {
+	// The example ordering of PEP 440's "Summary of permitted suffixes and
+	// relative ordering", extended by local versions and an epoch
+	testAscending(t, ComparePEP440, []string{
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0+abc.7",
+		"1.0+5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.1.dev1",
+		"1!0.1",
+	})
+}
+
+func TestComparePEP440Equivalent(t *testing.T) {
+	tests := [][2]string{
+		{"1.0", "1.0.0"},
+		{"1.0alpha1", "1.0a1"},
+		{"1.0-beta.2", "1.0b2"},
+		{"1.0c1", "1.0rc1"},
+		{"1.0-1", "1.0.post1"},
+		{"1.0.rev1", "1.0.post1"},
+		{"v1.0DEV", "1.0.dev0"},
+		{"0!1.0", "1.0"},
+	}
+	for _, tt := range tests {
+		got, err := ComparePEP440(tt[0], tt[1])
+		if err != nil {
+			t.Fatalf("ComparePEP440(%q, %q): %v", tt[0], tt[1], err)
+		}
+		if got != Equal {
+			t.Errorf("ComparePEP440(%q, %q) = %v, want Equal", tt[0], tt[1], got)
+		}
+	}
+}
+
+func TestParsePEP440Invalid(t *testing.T) {
+	for _, v := range []string{"", "1.0-", "one", "1.0+", "1.0a1b2"} {
+		if _, err := ParsePEP440(v); err == nil {
+			t.Errorf("ParsePEP440(%q) succeeded, want error", v)
+		}
+	}
+}
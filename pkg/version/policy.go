@@ -0,0 +1,290 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UpdateType classifies the difference between two versions.
+type UpdateType string
+
+const (
+	UpdateNone       UpdateType = ""
+	UpdateMajor      UpdateType = "major"
+	UpdateMinor      UpdateType = "minor"
+	UpdatePatch      UpdateType = "patch"
+	UpdatePrerelease UpdateType = "prerelease"
+	// Calendar versions YYYY.MM.MICRO change year, month or micro
+	UpdateCalVerYear  UpdateType = "calver-year"
+	UpdateCalVerMonth UpdateType = "calver-month"
+	UpdateCalVerMicro UpdateType = "calver-micro"
+)
+
+// Severity ranks update types: 3 for major updates, 2 for minor updates
+// and 1 for patch-level updates (including pre-releases), 0 for none.
+func (t UpdateType) Severity() int {
+	switch t {
+	case UpdateMajor, UpdateCalVerYear:
+		return 3
+	case UpdateMinor, UpdateCalVerMonth:
+		return 2
+	case UpdateNone:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// leadingNumbersRegex matches the optional epoch of a version, as in the
+// Debian and RPM "1:2.0-1" or the PEP 440 "1!2.0", and its first three
+// numeric components.
+var leadingNumbersRegex = regexp.MustCompile(`^(?:(\d+)[:!])?\D*(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// Classify returns the type of the update from current to latest, two
+// valid versions of scheme. Versions of schemes without the notion of
+// major and minor releases are classified by their leading numbers, and
+// changes to other parts are patch-level updates. A change of epoch is a
+// major update.
+func Classify(current, latest, scheme string) UpdateType {
+	if current == latest {
+		return UpdateNone
+	}
+
+	switch scheme {
+	case "semver":
+		currentVer, err1 := ParseSemVer(current)
+		latestVer, err2 := ParseSemVer(latest)
+		if err1 == nil && err2 == nil {
+			return ClassifyUpdate(currentVer, latestVer)
+		}
+
+	case "calver":
+		// Compare the numbers, as 2024.01.5 and 2024.1.6 share the month
+		currentParts, err1 := calVerParts(current)
+		latestParts, err2 := calVerParts(latest)
+		if err1 == nil && err2 == nil {
+			switch {
+			case currentParts[0] != latestParts[0]:
+				return UpdateCalVerYear
+			case currentParts[1] != latestParts[1]:
+				return UpdateCalVerMonth
+			default:
+				return UpdateCalVerMicro
+			}
+		}
+
+	case "loose":
+		currentVer, err1 := ParseLoose(current)
+		latestVer, err2 := ParseLoose(latest)
+		if err1 == nil && err2 == nil {
+			if updateType := classifyNumbers(currentVer.release(), latestVer.release()); updateType != UpdateNone {
+				return updateType
+			}
+			if currentVer.PreRelease != latestVer.PreRelease {
+				return UpdatePrerelease
+			}
+			return UpdatePatch
+		}
+
+	case "pep440":
+		currentVer, err1 := ParsePEP440(current)
+		latestVer, err2 := ParsePEP440(latest)
+		if err1 == nil && err2 == nil {
+			if currentVer.Epoch != latestVer.Epoch {
+				return UpdateMajor
+			}
+			if updateType := classifyNumbers(currentVer.Release, latestVer.Release); updateType != UpdateNone {
+				return updateType
+			}
+			if latestVer.PreLabel != "" || latestVer.Dev >= 0 {
+				return UpdatePrerelease
+			}
+			return UpdatePatch
+		}
+	}
+
+	currentEpoch, currentNumbers := leadingNumbers(current)
+	latestEpoch, latestNumbers := leadingNumbers(latest)
+	if currentEpoch != latestEpoch {
+		return UpdateMajor
+	}
+	updateType := classifyNumbers(currentNumbers, latestNumbers)
+	if updateType == UpdateNone {
+		return UpdatePatch
+	}
+	return updateType
+}
+
+// classifyNumbers compares the first three components of two releases,
+// missing components counting as zero.
+func classifyNumbers(current, latest []int) UpdateType {
+	types := []UpdateType{UpdateMajor, UpdateMinor, UpdatePatch}
+	for i, updateType := range types {
+		var c, l int
+		if i < len(current) {
+			c = current[i]
+		}
+		if i < len(latest) {
+			l = latest[i]
+		}
+		if c != l {
+			return updateType
+		}
+	}
+	return UpdateNone
+}
+
+// leadingNumbers returns the epoch of a version, 0 if it has none, and up
+// to three of its leading numbers.
+func leadingNumbers(version string) (int, []int) {
+	matches := leadingNumbersRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return 0, nil
+	}
+	epoch, _ := strconv.Atoi(matches[1])
+	var numbers []int
+	for i := 2; i < len(matches) && matches[i] != ""; i++ {
+		n, _ := strconv.Atoi(matches[i])
+		numbers = append(numbers, n)
+	}
+	return epoch, numbers
+}
+
+// calVerParts returns the year, month and micro of a YYYY.MM.MICRO
+// version.
+func calVerParts(version string) ([]int, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid calver format: %s", version)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calver number: %s", part)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// ClassifyUpdate returns the kind of update going from current to latest
+// is: the most significant component that differs, or "prerelease" if only
+// the pre-release does.
+func ClassifyUpdate(current, latest *Version) UpdateType {
+	switch {
+	case current.Major != latest.Major:
+		return UpdateMajor
+	case current.Minor != latest.Minor:
+		return UpdateMinor
+	case current.Patch != latest.Patch:
+		return UpdatePatch
+	case current.PreRelease != latest.PreRelease:
+		return UpdatePrerelease
+	default:
+		return UpdateNone
+	}
+}
+
+// UpdatePolicy restricts the versions a repository may be updated to:
+// "patch" allows versions of the current minor release, "minor" of the
+// current major release and "major" any version, while a semver range
+// (see ParseConstraint) allows the versions satisfying it. Either way
+// pre-releases are opt-in as described for Constraint, so the levels only
+// allow pre-releases of the current version if it is one itself.
+type UpdatePolicy struct {
+	Original   string
+	level      UpdateType
+	constraint *Constraint
+}
+
+func ParseUpdatePolicy(policy string) (*UpdatePolicy, error) {
+	p := &UpdatePolicy{Original: policy}
+	switch UpdateType(policy) {
+	case UpdateMajor, UpdateMinor, UpdatePatch:
+		p.level = UpdateType(policy)
+		return p, nil
+	}
+
+	constraint, err := ParseConstraint(policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update policy: %w", err)
+	}
+	p.constraint = constraint
+	return p, nil
+}
+
+// NeedsCurrent reports whether the policy is relative to the current
+// version, i.e. is one of "patch", "minor" and "major".
+func (p *UpdatePolicy) NeedsCurrent() bool {
+	return p.constraint == nil
+}
+
+// Allows reports whether the policy allows updating from current to
+// candidate. current may be nil for semver range policies.
+func (p *UpdatePolicy) Allows(current, candidate *Version) bool {
+	if p.constraint != nil {
+		return p.constraint.Check(candidate)
+	}
+	return levelConstraint(p.level, current).Check(candidate)
+}
+
+// AllowsVersion reports whether the policy allows updating from current to
+// candidate, two versions of scheme, and fails if current is not a valid
+// version. current may be "" for range policies. Other schemes than semver
+// have no pre-releases in the semver sense: levels allow the versions not
+// older than current whose update type (see Classify) is at most as
+// severe, and ranges the versions whose leading numbers satisfy them.
+func (p *UpdatePolicy) AllowsVersion(current, candidate, scheme string) (bool, error) {
+	if scheme == "semver" {
+		var currentVer *Version
+		if p.NeedsCurrent() {
+			var err error
+			if currentVer, err = ParseSemVer(current); err != nil {
+				return false, err
+			}
+		}
+		candidateVer, err := ParseSemVer(candidate)
+		if err != nil {
+			return false, nil
+		}
+		return p.Allows(currentVer, candidateVer), nil
+	}
+
+	if p.constraint != nil {
+		_, numbers := leadingNumbers(candidate)
+		if len(numbers) == 0 {
+			return false, nil
+		}
+		numbers = append(numbers, 0, 0)
+		return p.constraint.Check(&Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}), nil
+	}
+
+	if _, err := Compare(current, current, scheme); err != nil {
+		return false, err
+	}
+	if result, err := Compare(current, candidate, scheme); err != nil || result == Greater {
+		return false, nil
+	}
+	return Classify(current, candidate, scheme).Severity() <= p.level.Severity(), nil
+}
+
+// levelConstraint returns the range of versions level allows updating to
+// from current: >=current, below the next minor release for "patch" (as
+// with "~") and below the next major release for "minor".
+func levelConstraint(level UpdateType, current *Version) *Constraint {
+	set := []comparator{{">=", current}}
+	switch level {
+	case UpdatePatch:
+		set = append(set, comparator{"<", &Version{Major: current.Major, Minor: current.Minor + 1}})
+	case UpdateMinor:
+		set = append(set, comparator{"<", &Version{Major: current.Major + 1}})
+	}
+	return &Constraint{Original: string(level), sets: [][]comparator{set}}
+}
+
+func (p *UpdatePolicy) String() string {
+	return p.Original
+}
@@ -0,0 +1,194 @@
+package version
+
+import "testing"
+
+func TestUpdatePolicyAllows(t *testing.T) {
+	tests := []struct {
+		policy    string
+		current   string
+		candidate string
+		want      bool
+	}{
+		{"patch", "1.2.0", "1.2.5", true},
+		{"patch", "1.2.0", "1.3.0", false},
+		{"minor", "1.0.0", "1.9.0", true},
+		{"minor", "1.0.0", "2.0.0", false},
+		{"minor", "0.3.1", "0.9.0", true},
+		{"major", "1.0.0", "3.0.0", true},
+		{"~1.2", "1.0.0", "1.2.9", true},
+		{"~1.2", "1.0.0", "1.3.0", false},
+		{">=1.2 <2", "1.0.0", "1.9.9", true},
+		// Pre-releases are opt-in for levels and ranges alike
+		{"minor", "1.0.0", "1.3.0-rc.1", false},
+		{"^1.0", "1.0.0", "1.3.0-rc.1", false},
+		{"patch", "1.2.0", "1.2.1-beta", false},
+		{"major", "1.0.0", "2.0.0-rc.1", false},
+		{"minor", "1.3.0-rc.0", "1.3.0-rc.1", true},
+		{"patch", "2.0.0-rc.1", "2.0.0-rc.2", true},
+		{">=2.0.0-rc.0", "1.0.0", "2.0.0-rc.1", true},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParseUpdatePolicy(tt.policy)
+		if err != nil {
+			t.Fatalf("ParseUpdatePolicy(%q): %v", tt.policy, err)
+		}
+		current, err := ParseSemVer(tt.current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		candidate, err := ParseSemVer(tt.candidate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := policy.Allows(current, candidate); got != tt.want {
+			t.Errorf("policy %q from %s: Allows(%s) = %v, want %v", tt.policy, tt.current, tt.candidate, got, tt.want)
+		}
+	}
+}
+
+func TestUpdatePolicyAllowsVersion(t *testing.T) {
+	tests := []struct {
+		policy    string
+		scheme    string
+		current   string
+		candidate string
+		want      bool
+	}{
+		{"minor", "semver", "1.0.0", "1.9.0", true},
+		{"minor", "semver", "1.0.0", "1.3.0-rc.1", false},
+		{"minor", "pep440", "2.31.0", "2.32.1", true},
+		{"minor", "pep440", "2.31.0", "3.0.0", false},
+		{"minor", "pep440", "2.31.0", "2.30.0", false},
+		{"patch", "pep440", "2.31", "2.31.0.1", true},
+		{"patch", "maven", "6.4.4.Final", "6.4.5.Final", true},
+		{"patch", "maven", "6.4.4.Final", "6.5.0.Final", false},
+		{"minor", "debian", "2.36.1-8", "2.37-1", true},
+		{"minor", "debian", "2.36.1-8", "3.0-1", false},
+		{"patch", "rpm", "3.0.7-25.el9", "3.0.7-27.el9", true},
+		{"patch", "rpm", "3.0.7-25.el9", "3.1.0-1.el9", false},
+		{"patch", "calver", "2024.05.1", "2024.05.3", true},
+		{"patch", "calver", "2024.05.1", "2024.06.0", false},
+		{"minor", "calver", "2024.05.1", "2024.12.0", true},
+		{"minor", "calver", "2024.05.1", "2025.01.0", false},
+		{"major", "loose", "1.28", "2.0", true},
+		{"major", "loose", "1.28", "1.27", false},
+		// Ranges are matched against the leading numbers
+		{"~2.31", "pep440", "", "2.31.5", true},
+		{"~2.31", "pep440", "", "2.32.0", false},
+		{"^6.4", "maven", "", "6.6.1.Final", true},
+		{"^6.4", "maven", "", "7.0.0.Final", false},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParseUpdatePolicy(tt.policy)
+		if err != nil {
+			t.Fatalf("ParseUpdatePolicy(%q): %v", tt.policy, err)
+		}
+		got, err := policy.AllowsVersion(tt.current, tt.candidate, tt.scheme)
+		if err != nil {
+			t.Errorf("policy %q from %s %s: AllowsVersion(%s): %v", tt.policy, tt.scheme, tt.current, tt.candidate, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("policy %q from %s %s: AllowsVersion(%s) = %v, want %v", tt.policy, tt.scheme, tt.current, tt.candidate, got, tt.want)
+		}
+	}
+
+	policy, err := ParseUpdatePolicy("minor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, scheme := range []string{"semver", "pep440", "calver"} {
+		if _, err := policy.AllowsVersion("not a version", "1.0.0", scheme); err == nil {
+			t.Errorf("AllowsVersion() with an invalid %s current version succeeded, want error", scheme)
+		}
+	}
+}
+
+func TestClassifyUpdate(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            UpdateType
+	}{
+		{"1.2.3", "2.0.0", UpdateMajor},
+		{"1.2.3", "1.3.0", UpdateMinor},
+		{"1.2.3", "1.2.4", UpdatePatch},
+		{"1.3.0-rc.1", "1.3.0", UpdatePrerelease},
+		{"1.2.3", "1.2.3+build.1", UpdateNone},
+	}
+
+	for _, tt := range tests {
+		current, err := ParseSemVer(tt.current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		latest, err := ParseSemVer(tt.latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ClassifyUpdate(current, latest); got != tt.want {
+			t.Errorf("ClassifyUpdate(%s, %s) = %q, want %q", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		current, latest, scheme string
+		want                    UpdateType
+	}{
+		{"1.2.3", "2.0.0", "semver", UpdateMajor},
+		{"1.2.3", "1.2.4-rc.1", "semver", UpdatePatch},
+		{"1.2.4-rc.1", "1.2.4", "semver", UpdatePrerelease},
+		{"1.2.3", "1.2.3", "semver", UpdateNone},
+		{"2024.05.1", "2025.01.0", "calver", UpdateCalVerYear},
+		{"2024.05.1", "2024.06.0", "calver", UpdateCalVerMonth},
+		{"2024.05.1", "2024.05.2", "calver", UpdateCalVerMicro},
+		{"2024.01.5", "2024.1.6", "calver", UpdateCalVerMicro},
+		{"2.31.0", "2.32.0", "pep440", UpdateMinor},
+		{"2.31.0", "2.31.0.post1", "pep440", UpdatePatch},
+		{"2.31.0", "2.32.0rc1", "pep440", UpdateMinor},
+		{"2.32.0rc1", "2.32.0", "pep440", UpdatePatch},
+		{"2.32.0", "2.32.1.dev1", "pep440", UpdatePatch},
+		{"2.32.0", "2.32.0.1rc1", "pep440", UpdatePrerelease},
+		{"2.0", "1!1.0", "pep440", UpdateMajor},
+		{"1.28", "1.29.0", "loose", UpdateMinor},
+		{"1.2.3-beta1", "1.2.3", "loose", UpdatePrerelease},
+		{"6.4.4.Final", "6.4.5.Final", "maven", UpdatePatch},
+		{"6.4.4.Final", "7.0.0.Final", "maven", UpdateMajor},
+		{"2.36.1-8", "2.36.1-9", "debian", UpdatePatch},
+		{"2.36.1-8", "2.37-1", "debian", UpdateMinor},
+		{"1:2.0-1", "2:1.0-1", "debian", UpdateMajor},
+		{"2.0-1", "1:2.0-1", "debian", UpdateMajor},
+		{"3.0.7-25.el9", "1:3.0.7-25.el9", "rpm", UpdateMajor},
+		{"3.0.7-25.el9", "3.0.7-27.el9", "rpm", UpdatePatch},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.current, tt.latest, tt.scheme); got != tt.want {
+			t.Errorf("Classify(%s, %s, %s) = %q, want %q", tt.current, tt.latest, tt.scheme, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateTypeSeverity(t *testing.T) {
+	tests := []struct {
+		updateType UpdateType
+		want       int
+	}{
+		{UpdateMajor, 3},
+		{UpdateCalVerYear, 3},
+		{UpdateMinor, 2},
+		{UpdateCalVerMonth, 2},
+		{UpdatePatch, 1},
+		{UpdatePrerelease, 1},
+		{UpdateCalVerMicro, 1},
+		{UpdateNone, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.updateType.Severity(); got != tt.want {
+			t.Errorf("%q.Severity() = %d, want %d", tt.updateType, got, tt.want)
+		}
+	}
+}
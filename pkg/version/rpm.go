@@ -0,0 +1,225 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RPMVersion is an RPM package EVR of the form [epoch:]version[-release],
+// e.g. "1:3.0.7-25.el9".
+type RPMVersion struct {
+	Original string
+	Epoch    int
+	Version  string
+	Release  string
+}
+
+func ParseRPMVersion(version string) (*RPMVersion, error) {
+	v := &RPMVersion{Original: version}
+	rest := strings.TrimSpace(version)
+
+	if rest == "" || strings.ContainsAny(rest, " \t") {
+		return nil, fmt.Errorf("invalid rpm version: %q", version)
+	}
+
+	if epoch, ver, found := strings.Cut(rest, ":"); found {
+		n, err := strconv.Atoi(epoch)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid rpm version epoch: %s", version)
+		}
+		v.Epoch = n
+		rest = ver
+	}
+
+	if i := strings.LastIndex(rest, "-"); i >= 0 {
+		v.Release = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	if rest == "" || !isRPMAlnum(rest[0]) {
+		return nil, fmt.Errorf("invalid rpm version: %s", version)
+	}
+	v.Version = rest
+
+	return v, nil
+}
+
+func CompareRPM(current, latest string) (CompareResult, error) {
+	currentVer, err := ParseRPMVersion(current)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse current version: %w", err)
+	}
+
+	latestVer, err := ParseRPMVersion(latest)
+	if err != nil {
+		return Equal, fmt.Errorf("failed to parse latest version: %w", err)
+	}
+
+	return currentVer.Compare(latestVer), nil
+}
+
+// Compare orders EVRs like rpm: by epoch, version and release. As in rpm
+// dependency matching, the release is only compared if both have one.
+func (v *RPMVersion) Compare(other *RPMVersion) CompareResult {
+	if result := compareInts(v.Epoch, other.Epoch); result != Equal {
+		return result
+	}
+	if result := compareInts(rpmVerCmp(v.Version, other.Version), 0); result != Equal {
+		return result
+	}
+	if v.Release == "" || other.Release == "" {
+		return Equal
+	}
+	return compareInts(rpmVerCmp(v.Release, other.Release), 0)
+}
+
+func isRPMAlpha(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isRPMAlnum(c byte) bool {
+	return isRPMAlpha(c) || isDigit(c)
+}
+
+// rpmVerCmp is rpm's rpmvercmp: versions are split into alphabetic and
+// numeric segments, '~' sorts before anything (even the end of the string)
+// and '^' sorts after the end of the string but before anything else.
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	at := func(s string, i int) byte {
+		if i < len(s) {
+			return s[i]
+		}
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isRPMAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isRPMAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		// Tilde sorts before everything else
+		if at(a, i) == '~' || at(b, j) == '~' {
+			if at(a, i) != '~' {
+				return 1
+			}
+			if at(b, j) != '~' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Caret sorts like tilde, except that a version that ends sorts
+		// before it
+		if at(a, i) == '^' || at(b, j) == '^' {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if a[i] != '^' {
+				return 1
+			}
+			if b[j] != '^' {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		endA, endB := i, j
+		isNum := isDigit(a[i])
+		if isNum {
+			for endA < len(a) && isDigit(a[endA]) {
+				endA++
+			}
+			for endB < len(b) && isDigit(b[endB]) {
+				endB++
+			}
+		} else {
+			for endA < len(a) && isRPMAlpha(a[endA]) {
+				endA++
+			}
+			for endB < len(b) && isRPMAlpha(b[endB]) {
+				endB++
+			}
+		}
+
+		// Numeric segments are always newer than alphabetic ones
+		if endB == j {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		segA, segB := a[i:endA], b[j:endB]
+		if isNum {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return compareOrdered(len(segA), len(segB))
+			}
+		}
+		if rc := strings.Compare(segA, segB); rc != 0 {
+			return rc
+		}
+
+		i, j = endA, endB
+	}
+
+	if i >= len(a) && j >= len(b) {
+		return 0
+	}
+	if i >= len(a) {
+		return -1
+	}
+	return 1
+}
+
+func FilterValidRPM(tags []string) []string {
+	var validTags []string
+	for _, tag := range tags {
+		if _, err := ParseRPMVersion(tag); err == nil {
+			validTags = append(validTags, tag)
+		}
+	}
+	return validTags
+}
+
+func SortRPM(tags []string) []string {
+	var versions []*RPMVersion
+	for _, tag := range tags {
+		if v, err := ParseRPMVersion(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) == Less
+	})
+
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.Original)
+	}
+
+	return sorted
+}
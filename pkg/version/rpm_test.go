@@ -0,0 +1,40 @@
+package version
+
+import "testing"
+
+func TestCompareRPM(t *testing.T) {
+	testAscending(t, CompareRPM, []string{
+		"1.0~rc1",
+		"1.0",
+		"1.0^git1",
+		"1.0a",
+		"1.0.1-1.el9",
+		"1.0.1-2.el9",
+		"1.0.1-10.el9",
+		"1.2",
+		"1.10",
+		"3.0.7-25.el9",
+		"1:1.0",
+		"1:3.0.7-25.el9",
+		"2:0.1",
+	})
+}
+
+func TestCompareRPMEquivalent(t *testing.T) {
+	tests := [][2]string{
+		{"0:1.0-1", "1.0-1"},
+		{"1.01", "1.1"},
+		{"1.0_1", "1.0.1"},
+		// The release is only compared if both versions have one
+		{"1.0", "1.0-5.el9"},
+	}
+	for _, tt := range tests {
+		got, err := CompareRPM(tt[0], tt[1])
+		if err != nil {
+			t.Fatalf("CompareRPM(%q, %q): %v", tt[0], tt[1], err)
+		}
+		if got != Equal {
+			t.Errorf("CompareRPM(%q, %q) = %v, want Equal", tt[0], tt[1], got)
+		}
+	}
+}